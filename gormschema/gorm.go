@@ -1,6 +1,7 @@
 package gormschema
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
@@ -8,8 +9,10 @@ import (
 	"io"
 	"maps"
 	"reflect"
+	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"ariga.io/atlas/sdk/recordriver"
 	"gorm.io/driver/mysql"
@@ -17,7 +20,10 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/driver/sqlserver"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	gormig "gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
 )
 
 type (
@@ -28,6 +34,21 @@ type (
 		config            *gorm.Config
 		beforeAutoMigrate []func(*gorm.DB) error
 		modelPos          map[any]string
+		logger            logger.Interface
+		schema            string
+		sessionKey        string
+		baseModels        []any
+		extensions        []string
+		strictRefs        bool
+		tablespace        string
+		redundantIndexes  bool
+		tableStorage      map[reflect.Type]map[string]string
+		rec               Recorder
+		extensionComments bool
+		lockTimeout       time.Duration
+		terminator        string
+		namer             schema.Namer
+		tableOptions
 	}
 	// Option configures the Loader.
 	Option func(*Loader)
@@ -67,6 +88,14 @@ func WithConfig(cfg *gorm.Config) Option {
 	}
 }
 
+// WithNamingStrategy sets the naming strategy of the tables, columns and indexes, overriding
+// the one of WithConfig. Hence, the DDL uses the naming rules of the application.
+func WithNamingStrategy(namer schema.Namer) Option {
+	return func(l *Loader) {
+		l.namer = namer
+	}
+}
+
 // WithJoinTable sets up a join table for the given model and field.
 // Deprecated: put the join tables alongside the models in the Load call.
 func WithJoinTable(model any, field string, jointable any) Option {
@@ -78,7 +107,8 @@ func WithJoinTable(model any, field string, jointable any) Option {
 }
 
 // WithStmtDelimiter sets the delimiter for the output.
-// The default delimiter is `;`.
+// The default delimiter is `;`, which terminates every statement, while other
+// delimiters separate the statements, and are not emitted after the last one.
 // This is helpful for SQL Server, which uses the GO keyword as a delimiter.
 func WithStmtDelimiter(delimiter string) Option {
 	return func(l *Loader) {
@@ -86,6 +116,15 @@ func WithStmtDelimiter(delimiter string) Option {
 	}
 }
 
+// WithStatementTerminator ends every statement of the output with the given terminator, e.g. `;`,
+// independent of the delimiter between statements (see WithStmtDelimiter). Hence, statements
+// delimited by GO on SQL Server still end with `;`. Statements are not terminated twice.
+func WithStatementTerminator(terminator string) Option {
+	return func(l *Loader) {
+		l.terminator = terminator
+	}
+}
+
 // WithModelPosition sets the model position in the output.
 // The position is used to generate the `-- atlas:pos` directive in the output.
 func WithModelPosition(pos map[any]string) Option {
@@ -94,9 +133,242 @@ func WithModelPosition(pos map[any]string) Option {
 	}
 }
 
+// WithLogger sets the GORM logger used by the loader sessions. Hence,
+// the generated statements are logged as they are executed by GORM.
+func WithLogger(l logger.Interface) Option {
+	return func(ld *Loader) {
+		ld.logger = l
+	}
+}
+
+// WithSchema places the generated tables in the given schema, e.g. "app". Table names
+// (including the ones returned by TableName methods, unless already qualified) are qualified
+// with the schema, while the names of their indexes and constraints are not, as indexes are
+// created in the schema of their table. On PostgreSQL, extensions are created in the schema too.
+func WithSchema(name string) Option {
+	return func(l *Loader) {
+		l.schema = name
+	}
+}
+
+// WithCanonicalTypes normalizes the column types that differ between dialects only by
+// their defaults. Strings without a size or type tag, that are not part of a key or an
+// index, are created as "text" (or "nvarchar(MAX)" on SQL Server) on all dialects.
+func WithCanonicalTypes() Option {
+	return func(l *Loader) {
+		l.canonicalTypes = true
+	}
+}
+
+// WithSessionKey sets the key of the recordriver session that records the statements
+// of Load, which defaults to "gorm". Loaders that run concurrently must use distinct keys,
+// as the statements recorded by loaders sharing a session are mixed.
+func WithSessionKey(key string) Option {
+	return func(l *Loader) {
+		l.sessionKey = key
+	}
+}
+
+// WithAddColumnIfNotExists loads the models incrementally, on top of the schema of the given
+// base models. The tables of the base models are not created, but altered to add the columns
+// they are missing using ALTER TABLE ... ADD COLUMN IF NOT EXISTS, while other tables are created
+// as usual. Only the columns are added to existing tables, not their indexes or constraints.
+// It is supported by PostgreSQL and MariaDB.
+func WithAddColumnIfNotExists(base ...any) Option {
+	return func(l *Loader) {
+		l.baseModels = base
+	}
+}
+
+// WithKnownOpClasses registers operator classes in addition to the builtin ones, e.g.
+// the operator classes of custom extensions. The access method of an index is inferred
+// from (and validated against) the method of its known operator classes, and on PostgreSQL,
+// the extensions that provide them are created before the tables.
+func WithKnownOpClasses(classes map[string]OpClassInfo) Option {
+	return func(l *Loader) {
+		if l.opClasses == nil {
+			l.opClasses = maps.Clone(builtinOpClasses)
+		}
+		for name, info := range classes {
+			l.opClasses[strings.ToLower(name)] = info
+		}
+	}
+}
+
+// WithStrictOpClasses reports the operator classes of index columns that are neither known (see
+// WithKnownOpClasses) nor qualified with their schema as errors on PostgreSQL, instead of warnings.
+// Unqualified custom operator classes must be registered using WithKnownOpClasses.
+func WithStrictOpClasses() Option {
+	return func(l *Loader) {
+		l.strictOpClasses = true
+	}
+}
+
+// WithStrictIndexColumns verifies that the columns of the Indexes() definitions (including the
+// columns referenced by expressions) are created by GORM, and reports the ones that are not, e.g.
+// the columns of fields tagged `gorm:"-:migration"` after their columns were renamed.
+func WithStrictIndexColumns() Option {
+	return func(l *Loader) {
+		l.strictColumns = true
+	}
+}
+
+// WithPredicateColumnWarnings verifies that the columns referenced by the Where clauses of the
+// Indexes() definitions exist on their models, and logs the ones that do not as warnings, e.g.
+// "delete_at IS NULL", as a misspelled column makes the partial index match nothing (or fail to
+// be created). Identifiers followed by parentheses, casts and SQL keywords are not checked.
+func WithPredicateColumnWarnings() Option {
+	return func(l *Loader) {
+		l.predicateWarnings = true
+	}
+}
+
+// WithStrictPredicateColumns is like WithPredicateColumnWarnings, but reports the unknown columns
+// of the Where clauses as errors.
+func WithStrictPredicateColumns() Option {
+	return func(l *Loader) {
+		l.strictPredicates = true
+	}
+}
+
+// WithExplicitSortDirection emits the sort direction of every column of the Indexes()
+// definitions, e.g. "asc" for columns without a Sort, to match tools that compare the DDL
+// with introspected schemas. Columns of indexes whose access method does not support
+// ordering (e.g. gin) are not changed.
+func WithExplicitSortDirection() Option {
+	return func(l *Loader) {
+		l.explicitSort = true
+	}
+}
+
+// WithDroppedIncludes drops the included columns of covering indexes on dialects that do not
+// support them (SQLite and MySQL), and logs a warning, instead of failing. Hence, the same models
+// can be loaded for all dialects, with plain indexes where covering indexes are not supported.
+func WithDroppedIncludes() Option {
+	return func(l *Loader) {
+		l.dropIncludes = true
+	}
+}
+
+// WithStrictPrefixLengths reports the prefix lengths of index columns (see Length) as errors on
+// dialects other than MySQL, instead of ignoring them.
+func WithStrictPrefixLengths() Option {
+	return func(l *Loader) {
+		l.strictLengths = true
+	}
+}
+
+// WithStrictAccessMethods reports the access methods (see IndexDefinition.Type) of PostgreSQL
+// indexes that are neither builtin (btree, hash, gist, gin, spgist and brin) nor the methods of
+// known operator classes (see WithKnownOpClasses) as errors, as they are likely misspelled.
+// The methods of extensions, e.g. "hnsw" of pgvector, must be registered with their classes.
+func WithStrictAccessMethods() Option {
+	return func(l *Loader) {
+		l.strictMethods = true
+	}
+}
+
+// WithStrictNullsNotDistinct reports the NullsNotDistinct definitions as errors on dialects that
+// support neither NULLS NOT DISTINCT nor imply it, e.g. MySQL, instead of ignoring them with a warning.
+func WithStrictNullsNotDistinct() Option {
+	return func(l *Loader) {
+		l.strictNulls = true
+	}
+}
+
+// WithContentHashedNames suffixes the names of the Indexes() definitions with a short hash of
+// their content (columns, type, predicate and column options), e.g. "idx_users_email_1a2b3c4d".
+// Hence, any change of a definition renames its index, and Atlas recreates it instead of
+// keeping a stale index of the same name. Renaming a field that keeps its column does not change
+// the hash, and names are truncated before the suffix to fit 63 bytes. UNIQUE constraints (see
+// ConstraintStyle) keep their names.
+func WithContentHashedNames() Option {
+	return func(l *Loader) {
+		l.hashNames = true
+	}
+}
+
+// WithExtensions creates the given extensions on PostgreSQL, in addition to the ones
+// required by the models (see Loader.ExtractRequiredExtensions), e.g. "citext" or "uuid-ossp".
+// Extensions are pinned to a version using the "name:version" form, e.g. "pg_trgm:1.6".
+func WithExtensions(names ...string) Option {
+	return func(l *Loader) {
+		l.extensions = append(l.extensions, names...)
+	}
+}
+
+// WithExtensionComments precedes each CREATE EXTENSION statement with a comment naming
+// the indexes whose operator classes require the extension, e.g. "-- required by idx_name".
+func WithExtensionComments() Option {
+	return func(l *Loader) {
+		l.extensionComments = true
+	}
+}
+
+// WithoutReferencedUniqueIndexes disables the creation of unique indexes for the columns referenced
+// by foreign keys, that are neither a primary key nor unique. Instead, such references are reported
+// as errors, as the referenced columns of a foreign key must be unique.
+func WithoutReferencedUniqueIndexes() Option {
+	return func(l *Loader) {
+		l.strictRefs = true
+	}
+}
+
+// WithDefaultTablespace creates the tables and indexes in the given tablespace, unless
+// their definition sets their own (see IndexDefinition.Tablespace). It is supported by
+// PostgreSQL, and ignored by dialects without tablespaces.
+func WithDefaultTablespace(name string) Option {
+	return func(l *Loader) {
+		l.tablespace = name
+	}
+}
+
+// WithRedundantIndexWarnings reports the indexes whose columns are a left-prefix of the columns
+// of another index of their table, as the latter can usually serve their queries. The reports are
+// advisory: they are logged as warnings using the logger of the session, and never fail the load.
+func WithRedundantIndexWarnings() Option {
+	return func(l *Loader) {
+		l.redundantIndexes = true
+	}
+}
+
+// WithTableStorage sets the storage parameters of the tables of the given models, e.g.
+// {&models.Event{}: {"fillfactor": "90"}}, that are rendered as the WITH (...) clause of
+// their CREATE TABLE statements in the order of their names. It is supported by PostgreSQL,
+// and ignored by other dialects.
+func WithTableStorage(params map[any]map[string]string) Option {
+	return func(l *Loader) {
+		if l.tableStorage == nil {
+			l.tableStorage = make(map[reflect.Type]map[string]string)
+		}
+		for m, p := range params {
+			l.tableStorage[indirect(reflect.TypeOf(m))] = p
+		}
+	}
+}
+
+// WithRecorder records the statements of Load using the given recorder instead of the
+// recordriver session of the loader. Hence, statements can be captured without global
+// session state, e.g. by concurrent loaders.
+func WithRecorder(r Recorder) Option {
+	return func(l *Loader) {
+		l.rec = r
+	}
+}
+
+// WithLockTimeout limits the time the DDL statements wait for locks, e.g. on busy tables, by
+// setting the lock timeout of the session before the statements and resetting it after them.
+// It is supported by PostgreSQL (lock_timeout), MySQL (lock_wait_timeout, rounded up to whole
+// seconds) and SQL Server (LOCK_TIMEOUT), and ignored by SQLite and for non-positive durations.
+func WithLockTimeout(d time.Duration) Option {
+	return func(l *Loader) {
+		l.lockTimeout = d
+	}
+}
+
 // New returns a new Loader.
 func New(dialect string, opts ...Option) *Loader {
-	l := &Loader{dialect: dialect, delimiter: ";", config: &gorm.Config{}}
+	l := &Loader{dialect: dialect, delimiter: ";", config: &gorm.Config{}, sessionKey: "gorm"}
 	for _, opt := range opts {
 		opt(l)
 	}
@@ -138,12 +410,437 @@ func BuildStmt(fn func(db *gorm.DB) *gorm.DB) ViewOption {
 	})
 }
 
-// Load loads the models and returns the DDL statements representing the schema.
+// Load loads the models and returns the DDL statements representing the schema. The
+// Indexes() and TableName() methods of the models are discovered reflectively, hence
+// models defined in other packages can be loaded even if their types are not exported.
 func (l *Loader) Load(models ...any) (string, error) {
+	var buf strings.Builder
+	if err := l.LoadTo(&buf, models...); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// LoadTo is like Load, but writes the DDL statements to the given writer, e.g. a file or
+// os.Stdout, instead of returning them. Each statement is written on its own, followed by
+// its terminator and the delimiter of the loader (see WithStmtDelimiter).
+func (l *Loader) LoadTo(w io.Writer, models ...any) error {
+	return l.render(w, l.rec, nil, models...)
+}
+
+// render creates the models using the recording sessions of the loader, and writes their
+// statements to w. The statements are captured by rec, if set, and passed to exec, if set,
+// right before they are written.
+func (l *Loader) render(w io.Writer, rec Recorder, exec func([]string) error, models ...any) error {
+	di, err := l.dialector()
+	if err != nil {
+		return err
+	}
+	cfg := detach(l.config)
+	l.configure(&cfg)
+	ccfg := cfg
+	db, err := gorm.Open(l.tablesDialector(di), &cfg)
+	if err != nil {
+		return err
+	}
+	cdb, err := gorm.Open(dialector{Dialector: di, strictRefs: l.strictRefs}, &ccfg)
+	if err != nil {
+		return err
+	}
+	if rec == nil {
+		return l.load(w, db, cdb, sessionRecorder(l.sessionKey), exec, models...)
+	}
+	db = session(db, capture(db.Config, rec))
+	cdb = session(cdb, capture(cdb.Config, rec))
+	return l.load(w, db, cdb, rec, exec, models...)
+}
+
+// dialector returns the dialector of the loader, recording the statements of its session.
+func (l *Loader) dialector() (gorm.Dialector, error) {
+	var di gorm.Dialector
+	switch l.dialect {
+	case "sqlite":
+		rd, err := sql.Open("recordriver", l.sessionKey)
+		if err != nil {
+			return nil, err
+		}
+		di = sqlite.Dialector{Conn: rd}
+		recordriver.SetResponse(l.sessionKey, "select sqlite_version()", &recordriver.Response{
+			Cols: []string{"sqlite_version()"},
+			Data: [][]driver.Value{{"3.30.1"}},
+		})
+	case "mysql", "mariadb":
+		di = mysql.New(mysql.Config{
+			DriverName: "recordriver",
+			DSN:        l.sessionKey,
+		})
+		version := "8.0.24"
+		if l.dialect == "mariadb" {
+			// MariaDB is detected by GORM using its version.
+			version = "10.11.6-MariaDB"
+		}
+		recordriver.SetResponse(l.sessionKey, "SELECT VERSION()", &recordriver.Response{
+			Cols: []string{"VERSION()"},
+			Data: [][]driver.Value{{version}},
+		})
+	case "postgres":
+		di = postgres.New(postgres.Config{
+			DriverName: "recordriver",
+			DSN:        l.sessionKey,
+		})
+	case "sqlserver":
+		di = sqlserver.New(sqlserver.Config{
+			DriverName: "recordriver",
+			DSN:        l.sessionKey,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported engine: %s", l.dialect)
+	}
+	return di, nil
+}
+
+// detach returns a copy of the given config without the schema cache and callbacks of the
+// sessions opened with it, e.g. a config passed to WithConfig that was used to open a session.
+// Hence, the sessions of a load parse the models on their own, and the schemas they change, e.g.
+// tables qualified by WithSchema, are not shared with the sessions of the caller.
+func detach(cfg *gorm.Config) gorm.Config {
+	var c gorm.Config
+	src, dst := reflect.ValueOf(cfg).Elem(), reflect.ValueOf(&c).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if src.Type().Field(i).IsExported() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	return c
+}
+
+// capture returns a copy of the given config, that records the statements of its connection pool.
+func capture(cfg *gorm.Config, rec Recorder) *gorm.Config {
+	c := *cfg
+	c.ConnPool = &capturePool{ConnPool: cfg.ConnPool, rec: rec}
+	return &c
+}
+
+// LoadWithDB is like Load, but also executes the statements using the given session, in the
+// order they are returned. The statements are rendered the same way as by Load, and executed
+// only once all of them were rendered. Hence, the returned statements are exactly the ones that
+// ran, and the generated schema can be validated against a live database. The dialect of the
+// session must match the dialect of the loader.
+func (l *Loader) LoadWithDB(db *gorm.DB, models ...any) (string, error) {
+	name := db.Dialector.Name()
+	if isMariaDB(db) {
+		name = "mariadb"
+	}
+	if name != l.dialect {
+		return "", fmt.Errorf("session dialect %q does not match loader dialect %q", name, l.dialect)
+	}
+	var rec Recorder = &memRecorder{}
+	if l.rec != nil {
+		rec = l.rec
+	}
+	var buf strings.Builder
+	err := l.render(&buf, rec, func(stmts []string) error {
+		for _, stmt := range stmts {
+			if err := db.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}, models...)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// LoadDown returns the statements that tear down the schema that Load creates for the given
+// models, in the reverse order of their creation: foreign keys, views, indexes, tables and
+// extensions. Every drop is guarded with IF EXISTS, hence re-running the teardown is safe even
+// if some objects are already gone. MySQL supports the guard neither on indexes nor on foreign
+// keys. Hence, its indexes are dropped with their tables, and foreign key checks are disabled
+// during the teardown instead of dropping the foreign keys. Indexes built concurrently (see
+// IndexDefinition.Concurrent) are dropped concurrently on PostgreSQL.
+func (l *Loader) LoadDown(models ...any) (string, error) {
+	recorded, _ := l.recorder().Statements()
+	n := len(recorded)
+	// The final statements are captured, as the recorded ones are changed before they are written.
+	var stmts []string
+	if err := l.render(io.Discard, l.rec, func(s []string) error {
+		stmts = s
+		return nil
+	}, models...); err != nil {
+		return "", err
+	}
+	var drops []string
+	for i := len(stmts) - 1; i >= n; i-- {
+		drops = append(drops, l.dropStmts(stmts[i])...)
+	}
+	if l.dialect == "mysql" {
+		drops = append(append([]string{"SET FOREIGN_KEY_CHECKS = 0"}, drops...), "SET FOREIGN_KEY_CHECKS = 1")
+	}
+	var buf strings.Builder
+	// Like their builds, concurrent drops cannot run in a transaction.
+	if slices.ContainsFunc(drops, concurrentIndex.MatchString) {
+		buf.WriteString("-- atlas:txmode none\n\n")
+	}
+	if err := l.write(&buf, drops); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// AlterColumnTypes returns the statements that change the types of the columns whose types differ
+// between the old and the new models, matched by their tables and column names. Other changes
+// are ignored. As automatic conversions might lose data, the new models can declare the conversions
+// of their columns using a TypeCasts() map[string]string method that maps a field name to the USING
+// expression of its column, e.g. {"Amount": "amount::numeric"}, which only PostgreSQL supports.
+// The other attributes of the columns, e.g. their nullability, are kept. SQLite does not support
+// altering the types of columns.
+func (l *Loader) AlterColumnTypes(oldModels, newModels []any) (string, error) {
+	if l.dialect == "sqlite" {
+		return "", errors.New("sqlite does not support altering column types")
+	}
+	di, err := l.dialector()
+	if err != nil {
+		return "", err
+	}
+	cfg := detach(l.config)
+	l.configure(&cfg)
+	cfg.DryRun = true
+	db, err := gorm.Open(l.tablesDialector(di), &cfg)
+	if err != nil {
+		return "", err
+	}
+	oldTypes := make(map[string]map[string]string)
+	for _, model := range oldModels {
+		table, types, _, err := columnTypes(db, model)
+		if err != nil {
+			return "", err
+		}
+		oldTypes[table] = types
+	}
+	var stmts []string
+	for _, model := range newModels {
+		table, types, order, err := columnTypes(db, model)
+		if err != nil {
+			return "", err
+		}
+		var casts map[string]string
+		if c, ok := receiver(model).Interface().(interface{ TypeCasts() map[string]string }); ok {
+			casts = c.TypeCasts()
+		}
+		changed := make(map[string]bool)
+		for _, f := range order {
+			prev, ok := oldTypes[table][f.DBName]
+			if !ok || strings.EqualFold(prev, types[f.DBName]) {
+				continue
+			}
+			changed[f.Name] = true
+			q := db.Statement.Quote
+			switch l.dialect {
+			case "postgres":
+				stmt := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s", q(table), q(f.DBName), types[f.DBName])
+				if cast := strings.TrimSpace(casts[f.Name]); cast != "" {
+					stmt += " USING " + cast
+				}
+				stmts = append(stmts, stmt)
+			case "sqlserver":
+				// ALTER COLUMN resets the nullability of the column, hence it is set explicitly. Its
+				// default is a constraint of the table, that is kept as-is and cannot be set here.
+				null := " NULL"
+				if f.NotNull || f.PrimaryKey {
+					null = " NOT NULL"
+				}
+				stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s%s", q(table), q(f.DBName), types[f.DBName], null))
+			default:
+				// MODIFY COLUMN replaces the definition of the column, hence it is rendered in
+				// full, e.g. with its nullability, default and AUTO_INCREMENT attribute.
+				stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", q(table), q(f.DBName), db.Migrator().FullDataTypeOf(f).SQL))
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(casts)) {
+			switch {
+			case strings.Contains(casts[name], ";"):
+				return "", fmt.Errorf("type cast of %q: expression must not contain ';'", name)
+			case !changed[name]:
+				return "", fmt.Errorf("type cast of %q: the type of its column is not changed", name)
+			case l.dialect != "postgres":
+				return "", fmt.Errorf("type cast of %q: USING expressions are supported only by PostgreSQL", name)
+			}
+		}
+	}
+	var buf strings.Builder
+	if err := l.write(&buf, stmts); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// columnTypes returns the table of the model, the types of its columns keyed
+// by their names, and the fields of its columns in their order.
+func columnTypes(db *gorm.DB, model any) (string, map[string]string, []*schema.Field, error) {
+	tx, value, err := migrationTarget(db, model)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(value); err != nil {
+		return "", nil, nil, err
+	}
+	table := tx.Statement.Table
+	if table == "" {
+		table = stmt.Schema.Table
+	}
+	types := make(map[string]string)
+	var fields []*schema.Field
+	for _, f := range stmt.Schema.Fields {
+		if f.DBName == "" || f.IgnoreMigration {
+			continue
+		}
+		types[f.DBName] = dataTypeOf(tx, f)
+		fields = append(fields, f)
+	}
+	return table, types, fields, nil
+}
+
+// identExpr matches an identifier as quoted by the dialects, optionally qualified with its schema.
+const identExpr = "(?:\"[^\"]+\"|`[^`]+`|\\[[^\\]]+\\]|[^\\s(.\"`]+)"
+
+var (
+	createTable     = regexp.MustCompile(`^CREATE TABLE (` + identExpr + `(?:\.` + identExpr + `)?)`)
+	createIndex     = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX (?:CONCURRENTLY )?(?:IF NOT EXISTS )?(` + identExpr + `) ON (?:(` + identExpr + `)\.)?(` + identExpr + `)`)
+	concurrentIndex = regexp.MustCompile(`^(?:CREATE (?:UNIQUE )?|DROP )INDEX CONCURRENTLY `)
+	createView      = regexp.MustCompile(`^CREATE (?:OR REPLACE )?VIEW (` + identExpr + `(?:\.` + identExpr + `)?)`)
+	createExt       = regexp.MustCompile(`^CREATE EXTENSION IF NOT EXISTS (` + identExpr + `)`)
+	leadingComments = regexp.MustCompile(`^(?:--[^\n]*\n)+`)
+	addFK           = regexp.MustCompile(`^ALTER TABLE (` + identExpr + `(?:\.` + identExpr + `)?) ADD CONSTRAINT (` + identExpr + `) FOREIGN KEY`)
+)
+
+// dropStmts returns the statements that drop the object created by the given statement,
+// if any. Objects that are dropped with their tables, e.g. triggers, are skipped.
+func (l *Loader) dropStmts(stmt string) []string {
+	stmt = leadingComments.ReplaceAllString(stmt, "")
+	if m := addFK.FindStringSubmatch(stmt); m != nil {
+		switch l.dialect {
+		case "mysql":
+			return nil
+		case "mariadb":
+			return []string{fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY IF EXISTS %s", m[1], m[2])}
+		default:
+			return []string{fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", m[1], m[2])}
+		}
+	}
+	if m := createIndex.FindStringSubmatch(stmt); m != nil {
+		table := m[3]
+		if m[2] != "" {
+			table = m[2] + "." + m[3]
+		}
+		switch l.dialect {
+		case "mysql":
+			return nil
+		case "mariadb", "sqlserver":
+			return []string{fmt.Sprintf("DROP INDEX IF EXISTS %s ON %s", m[1], table)}
+		case "postgres":
+			drop := "DROP INDEX IF EXISTS "
+			if concurrentIndex.MatchString(stmt) {
+				drop = "DROP INDEX CONCURRENTLY IF EXISTS "
+			}
+			// Indexes are created in the schema of their table.
+			if m[2] != "" {
+				return []string{drop + m[2] + "." + m[1]}
+			}
+			return []string{drop + m[1]}
+		}
+		return []string{"DROP INDEX IF EXISTS " + m[1]}
+	}
+	if m := createTable.FindStringSubmatch(stmt); m != nil {
+		return []string{"DROP TABLE IF EXISTS " + m[1]}
+	}
+	if m := createView.FindStringSubmatch(stmt); m != nil {
+		return []string{"DROP VIEW IF EXISTS " + m[1]}
+	}
+	if m := createExt.FindStringSubmatch(stmt); m != nil {
+		return []string{"DROP EXTENSION IF EXISTS " + m[1]}
+	}
+	return nil
+}
+
+// qualifyTablers qualifies the table names returned by the TableName methods of the given models
+// with the schema of the loader, as GORM uses them as-is instead of passing them to the naming
+// strategy. The schemas of the models are cached by each session, hence they are changed in place
+// before they are migrated or referenced by other models. The sessions of a load do not share
+// their cache with other sessions (see detach), hence the changes do not outlive the load.
+func (l *Loader) qualifyTablers(models []any, dbs ...*gorm.DB) error {
+	for _, db := range dbs {
+		for _, model := range models {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return err
+			}
+			if !strings.Contains(stmt.Schema.Table, ".") {
+				stmt.Schema.Table = l.schema + "." + stmt.Schema.Table
+			}
+		}
+	}
+	return nil
+}
+
+// configure applies the options of the loader to the given config.
+func (l *Loader) configure(cfg *gorm.Config) {
+	if l.logger != nil {
+		cfg.Logger = l.logger
+	}
+	if l.namer != nil {
+		cfg.NamingStrategy = l.namer
+	}
+	if l.schema != "" {
+		ns := cfg.NamingStrategy
+		if ns == nil {
+			ns = schema.NamingStrategy{IdentifierMaxLength: 64}
+		}
+		cfg.NamingStrategy = schemaNamer{Namer: ns, schema: l.schema}
+	}
+	if l.dialect == "postgres" {
+		ns := cfg.NamingStrategy
+		if ns == nil {
+			ns = schema.NamingStrategy{IdentifierMaxLength: 64}
+		}
+		cfg.NamingStrategy = foldNamer{Namer: ns}
+	}
+}
+
+// tablesDialector returns the dialector used to create the tables.
+func (l *Loader) tablesDialector(di gorm.Dialector) gorm.Dialector {
+	return tableDialector{Dialector: di, tableOptions: &l.tableOptions}
+}
+
+// session returns a new session of db that uses the given config and its connection pool.
+func session(db *gorm.DB, cfg *gorm.Config) *gorm.DB {
+	tx := db.Session(&gorm.Session{NewDB: true, Context: db.Statement.Context})
+	tx.Config = cfg
+	tx.Statement.ConnPool = cfg.ConnPool
+	return tx
+}
+
+// load creates the models using the given sessions: db creates the tables, and cdb creates
+// the views, triggers and constraints. The final statements are passed to exec, if set, before
+// they are written to w.
+func (l *Loader) load(w io.Writer, db, cdb *gorm.DB, rec Recorder, exec func([]string) error, models ...any) error {
 	var (
 		views  []ViewDefiner
 		tables []any
 	)
+	// The recorded statements are changed in out, as the recorder might return a copy of them.
+	recorded, _ := rec.Statements()
+	out, seen := slices.Clone(recorded), len(recorded)
+	// flush appends the statements recorded since the last flush to out, and returns them.
+	flush := func() []string {
+		recorded, _ := rec.Statements()
+		n := len(out)
+		out = append(out, recorded[seen:]...)
+		seen = len(recorded)
+		return out[n:]
+	}
 	for _, obj := range models {
 		switch view := obj.(type) {
 		case ViewDefiner:
@@ -152,103 +849,447 @@ func (l *Loader) Load(models ...any) (string, error) {
 			tables = append(tables, obj)
 		}
 	}
-	var di gorm.Dialector
+	fks := !db.Config.DisableForeignKeyConstraintWhenMigrating && l.dialect != "sqlite"
+	if l.dialect != "sqlite" {
+		db.Config.DisableForeignKeyConstraintWhenMigrating = true
+	}
+	if l.schema != "" {
+		if err := l.qualifyTablers(tables, db, cdb); err != nil {
+			return err
+		}
+	}
+	// Join tables are set up on both sessions, as the
+	// constraints are created by the custom migrator.
+	for _, cb := range l.beforeAutoMigrate {
+		if err := cb(db); err != nil {
+			return err
+		}
+		if err := cb(cdb); err != nil {
+			return err
+		}
+	}
+	cm, ok := cdb.Migrator().(*migrator)
+	if !ok {
+		return fmt.Errorf("unexpected migrator type: %T", db.Migrator())
+	}
+	if err := cm.setupJoinTables(tables...); err != nil {
+		return err
+	}
+	orderedTables, err := cm.orderModels(tables...)
+	if err != nil {
+		return err
+	}
+
+	set, reset := l.lockTimeoutStmts()
+	if set != "" {
+		if err := db.Exec(set).Error; err != nil {
+			return err
+		}
+	}
+	// Extensions are created before the tables that use their operator classes.
+	if l.dialect == "postgres" {
+		required, indexes, err := requiredExtensions(l.extensions, tables, knownOpClasses(db))
+		if err != nil {
+			return err
+		}
+		for _, ext := range required {
+			var comment, schema, version string
+			if names := indexes[ext.name]; l.extensionComments && len(names) > 0 {
+				// The comment is part of the statement, hence it is kept on statement splitting.
+				comment = fmt.Sprintf("-- required by %s\n", strings.Join(names, ", "))
+			}
+			if l.schema != "" {
+				schema = " SCHEMA " + db.Statement.Quote(l.schema)
+			}
+			if ext.version != "" {
+				version = fmt.Sprintf(" VERSION '%s'", ext.version)
+			}
+			if err := db.Exec(comment + "CREATE EXTENSION IF NOT EXISTS " + db.Statement.Quote(ext.name) + schema + version).Error; err != nil {
+				return err
+			}
+		}
+	}
+	baseColumns, err := l.baseColumns(db)
+	if err != nil {
+		return err
+	}
+	// Models are created one by one, as each might be migrated using its own clone (see
+	// AutoMigrateModel). Hence, dependencies are resolved once, instead of on every call.
+	var (
+		alters   []uniqueConstraint
+		comments []constraintComment
+		created  []any
+	)
+	for _, model := range db.Migrator().(interface {
+		ReorderModels([]any, bool) []any
+	}).ReorderModels(orderedTables, true) {
+		if len(baseColumns) > 0 {
+			added, err := addColumns(db, model, baseColumns)
+			if err != nil {
+				return err
+			}
+			if added {
+				continue
+			}
+		}
+		created = append(created, model)
+		cs, err := uniqueConstraints(db, model)
+		if err != nil {
+			return err
+		}
+		checks, err := tableChecks(db, model)
+		if err != nil {
+			return err
+		}
+		cc, err := constraintComments(db, model, cs, checks, fks)
+		if err != nil {
+			return err
+		}
+		comments = append(comments, cc...)
+		ai, err := autoIncrementStart(db, model)
+		if err != nil {
+			return err
+		}
+		flush()
+		if err := createModel(db, model); err != nil {
+			return err
+		}
+		// GORM creates the indexes of a table in map order.
+		stmts := flush()
+		restoreSemicolons(stmts)
+		inlineConstraints(db, stmts, cs, checks)
+		sortIndexes(stmts)
+		if ai != nil {
+			ai.inline(db, stmts)
+		}
+		if names := flaggedIndexes(db, model, "Concurrent"); len(names) > 0 {
+			if l.dialect == "postgres" {
+				concurrently(db, stmts, names)
+			} else {
+				for _, name := range names {
+					db.Logger.Warn(context.Background(), "index %q: concurrent builds are supported only by PostgreSQL and were ignored", name)
+				}
+			}
+		}
+		if l.dialect != "postgres" && l.dialect != "sqlserver" {
+			for _, name := range flaggedIndexes(db, model, "NullsNotDistinct") {
+				db.Logger.Warn(context.Background(), "index %q: NULLS NOT DISTINCT is supported only by PostgreSQL and SQL Server and was ignored", name)
+			}
+		}
+		if params := l.tableStorage[indirect(reflect.TypeOf(model))]; len(params) > 0 && l.dialect == "postgres" {
+			if err := tableStorage(stmts, params); err != nil {
+				return err
+			}
+		}
+		if names := flaggedIndexes(db, model, "Replace"); len(names) > 0 {
+			out = append(out[:len(out)-len(stmts)], l.replaceIndexes(db, stmts, names)...)
+		}
+		if stmt, err := ai.restart(db); err != nil {
+			return err
+		} else if stmt != "" {
+			// PostgreSQL restarts the sequence of the serial column instead.
+			if err := db.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		table, index, err := clusterIndex(db, model)
+		if err != nil {
+			return err
+		}
+		if index != "" {
+			if err := db.Exec("CLUSTER ? USING ?", clause.Table{Name: table}, clause.Column{Name: index}).Error; err != nil {
+				return err
+			}
+		}
+		rls, err := rowLevelSecurity(db, model)
+		if err != nil {
+			return err
+		}
+		for _, s := range rls {
+			if err := db.Exec(s).Error; err != nil {
+				return err
+			}
+		}
+		for _, c := range cs {
+			if c.style == AlterConstraint {
+				alters = append(alters, c)
+			}
+		}
+	}
+	if l.redundantIndexes {
+		for _, model := range created {
+			rs, err := redundantIndexes(db, model)
+			if err != nil {
+				return err
+			}
+			for _, r := range rs {
+				db.Logger.Warn(context.Background(), "index %q of table %q is a prefix of index %q and might be redundant", r.name, r.table, r.by)
+			}
+		}
+	}
+	for _, c := range alters {
+		if err = db.Exec("ALTER TABLE ? ADD "+c.def(db), clause.Table{Name: c.table}).Error; err != nil {
+			return err
+		}
+	}
+	// Indexes are disabled once they all exist, including the ones of UNIQUE constraints.
+	for _, model := range created {
+		if names := flaggedIndexes(db, model, "Disabled"); len(names) > 0 {
+			if err := l.disableIndexes(db, model, names); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = cm.CreateViews(views); err != nil {
+		return err
+	}
+	if err = cm.CreateTriggers(models); err != nil {
+		return err
+	}
+	// Foreign keys are added only after all tables and their indexes were created,
+	// as they might reference unique indexes of tables that depend on them (circular).
+	if fks {
+		if len(baseColumns) > 0 {
+			tables = created
+		}
+		if err = cm.CreateConstraints(tables); err != nil {
+			return err
+		}
+	}
+	// Only PostgreSQL supports comments on constraints.
+	if l.dialect == "postgres" {
+		for _, c := range comments {
+			lit, err := sqlLiteral(l.dialect, reflect.ValueOf(c.comment))
+			if err != nil {
+				return err
+			}
+			q := db.Statement.Quote
+			if err = db.Exec(fmt.Sprintf("COMMENT ON CONSTRAINT %s ON %s IS %s", q(c.name), q(clause.Table{Name: c.table}), lit)).Error; err != nil {
+				return err
+			}
+		}
+	}
+	if reset != "" {
+		if err := db.Exec(reset).Error; err != nil {
+			return err
+		}
+	}
+	if _, ok := rec.Statements(); !ok {
+		return errors.New("gorm db session not found")
+	}
+	flush()
+	stmts := out
+	if l.tablespace != "" && l.dialect == "postgres" {
+		defaultTablespace(db, stmts, l.tablespace)
+	}
+	if exec != nil {
+		if err := exec(stmts); err != nil {
+			return err
+		}
+	}
+	if err = l.directives(w, cm, stmts); err != nil {
+		return err
+	}
+	return l.write(w, stmts)
+}
+
+// write writes the given statements to w, each on its own (see terminate).
+func (l *Loader) write(w io.Writer, stmts []string) error {
+	for i, stmt := range stmts {
+		if _, err := fmt.Fprintln(w, l.terminate(stmt, i == len(stmts)-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// terminate returns the given statement followed by its terminator and the delimiter of the output.
+// The default delimiter (;) terminates every statement, while other delimiters, e.g. GO, separate
+// the statements, hence they do not follow the last one.
+func (l *Loader) terminate(stmt string, last bool) string {
+	delimiter := l.delimiter
+	if last && delimiter != ";" {
+		delimiter = ""
+	}
+	if l.terminator == "" {
+		return stmt + delimiter
+	}
+	if !strings.HasSuffix(stmt, l.terminator) {
+		stmt += l.terminator
+	}
+	// The default delimiter terminates the statement already.
+	if delimiter != l.terminator {
+		stmt += delimiter
+	}
+	return stmt
+}
+
+// lockTimeoutStmts returns the statements setting and resetting the lock timeout of the session.
+func (l *Loader) lockTimeoutStmts() (set, reset string) {
+	d := l.lockTimeout
+	if d <= 0 {
+		return "", ""
+	}
+	// Timeouts are rounded up, as zero disables them.
+	ms := (d + time.Millisecond - 1) / time.Millisecond
 	switch l.dialect {
-	case "sqlite":
-		rd, err := sql.Open("recordriver", "gorm")
-		if err != nil {
-			return "", err
-		}
-		di = sqlite.Dialector{Conn: rd}
-		recordriver.SetResponse("gorm", "select sqlite_version()", &recordriver.Response{
-			Cols: []string{"sqlite_version()"},
-			Data: [][]driver.Value{{"3.30.1"}},
-		})
-	case "mysql":
-		di = mysql.New(mysql.Config{
-			DriverName: "recordriver",
-			DSN:        "gorm",
-		})
-		recordriver.SetResponse("gorm", "SELECT VERSION()", &recordriver.Response{
-			Cols: []string{"VERSION()"},
-			Data: [][]driver.Value{{"8.0.24"}},
-		})
 	case "postgres":
-		di = postgres.New(postgres.Config{
-			DriverName: "recordriver",
-			DSN:        "gorm",
-		})
+		v := fmt.Sprintf("%dms", ms)
+		if d%time.Second == 0 {
+			v = fmt.Sprintf("%ds", d/time.Second)
+		}
+		return fmt.Sprintf("SET lock_timeout = '%s'", v), "RESET lock_timeout"
+	case "mysql", "mariadb":
+		secs := (d + time.Second - 1) / time.Second
+		return fmt.Sprintf("SET SESSION lock_wait_timeout = %d", secs), "SET SESSION lock_wait_timeout = DEFAULT"
 	case "sqlserver":
-		di = sqlserver.New(sqlserver.Config{
-			DriverName: "recordriver",
-			DSN:        "gorm",
-		})
-	default:
-		return "", fmt.Errorf("unsupported engine: %s", l.dialect)
+		return fmt.Sprintf("SET LOCK_TIMEOUT %d", ms), "SET LOCK_TIMEOUT -1"
 	}
-	cfg := *l.config
-	db, err := gorm.Open(di, &cfg)
-	if err != nil {
-		return "", err
+	return "", ""
+}
+
+// baseColumns returns the columns of the tables of the base models (see WithAddColumnIfNotExists).
+func (l *Loader) baseColumns(db *gorm.DB) (map[string]map[string]bool, error) {
+	if len(l.baseModels) == 0 {
+		return nil, nil
 	}
-	if l.dialect != "sqlite" {
-		db.Config.DisableForeignKeyConstraintWhenMigrating = true
+	if l.dialect != "postgres" && l.dialect != "mariadb" {
+		return nil, fmt.Errorf("ADD COLUMN IF NOT EXISTS is not supported by %s", l.dialect)
 	}
-	for _, cb := range l.beforeAutoMigrate {
-		if err = cb(db); err != nil {
-			return "", err
+	tables := make(map[string]map[string]bool)
+	for _, model := range l.baseModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		if tables[stmt.Schema.Table] == nil {
+			tables[stmt.Schema.Table] = make(map[string]bool)
+		}
+		for _, name := range stmt.Schema.DBNames {
+			tables[stmt.Schema.Table][name] = true
 		}
 	}
-	cdb, err := gorm.Open(dialector{Dialector: di}, l.config)
-	if err != nil {
-		return "", err
+	return tables, nil
+}
+
+// addColumns adds the columns of the given model that are missing in its base table, and
+// reports whether the base schema has this table. Otherwise, the table should be created.
+func addColumns(db *gorm.DB, model any, base map[string]map[string]bool) (bool, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return false, err
 	}
-	cm, ok := cdb.Migrator().(*migrator)
+	columns, ok := base[stmt.Schema.Table]
 	if !ok {
-		return "", fmt.Errorf("unexpected migrator type: %T", db.Migrator())
-	}
-	if err = cm.setupJoinTables(tables...); err != nil {
-		return "", err
+		return false, nil
 	}
-	orderedTables, err := cm.orderModels(tables...)
+	// The columns are parsed from the migrated value, as it might be a clone.
+	tx, value, err := migrationTarget(db, model)
 	if err != nil {
-		return "", err
+		return false, err
 	}
-
-	for _, model := range orderedTables {
-		if err := AutoMigrateModel(db, model); err != nil {
-			return "", err
+	stmt = &gorm.Statement{DB: tx}
+	if err := stmt.ParseWithSpecialTableName(value, tx.Statement.Table); err != nil {
+		return false, err
+	}
+	for _, name := range stmt.Schema.DBNames {
+		if columns[name] {
+			continue
+		}
+		f := stmt.Schema.FieldsByDBName[name]
+		if err := db.Exec("ALTER TABLE ? ADD COLUMN IF NOT EXISTS ? ?",
+			clause.Table{Name: stmt.Schema.Table}, clause.Column{Name: name}, tx.Migrator().FullDataTypeOf(f)).Error; err != nil {
+			return false, err
 		}
 	}
+	return true, nil
+}
 
-	if err = cm.CreateViews(views); err != nil {
-		return "", err
-	}
-	if err = cm.CreateTriggers(models); err != nil {
-		return "", err
+// Recorder records the statements executed by the loader sessions. By default, Load uses the
+// recordriver session of the loader (see WithSessionKey), which is shared by all loaders using
+// the same key. Loaders with their own Recorder (see WithRecorder) do not share their statements.
+type Recorder interface {
+	// Record records a statement executed by the loader.
+	Record(stmt string)
+	// Statements returns the statements recorded so far, if any. The loader does not
+	// change the returned slice, hence implementations might return their own storage.
+	Statements() ([]string, bool)
+}
+
+// sessionRecorder is the recordriver session used by Load.
+type sessionRecorder string
+
+// Record is a no-op, as the statements are recorded by the driver of the session.
+func (sessionRecorder) Record(string) {}
+
+func (r sessionRecorder) Statements() ([]string, bool) {
+	s, ok := recordriver.Session(string(r))
+	if !ok {
+		return nil, false
 	}
-	if !l.config.DisableForeignKeyConstraintWhenMigrating && l.dialect != "sqlite" {
-		if err = cm.CreateConstraints(tables); err != nil {
-			return "", err
+	return s.Statements, true
+}
+
+// disableIndexes disables the given indexes of the model once they were created (see
+// IndexDefinition.Disabled). Other dialects than SQL Server ignore them with a warning.
+func (l *Loader) disableIndexes(db *gorm.DB, model any, names []string) error {
+	if l.dialect != "sqlserver" {
+		for _, name := range names {
+			db.Logger.Warn(context.Background(), "index %q: disabled indexes are supported only by SQL Server and were ignored", name)
 		}
+		return nil
 	}
-	s, ok := recordriver.Session("gorm")
-	if !ok {
-		return "", errors.New("gorm db session not found")
-	}
-	var buf strings.Builder
-	if err = l.directives(&buf, cm); err != nil {
-		return "", err
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
 	}
-	for _, stmt := range s.Statements {
-		if _, err = fmt.Fprintln(&buf, stmt+l.delimiter); err != nil {
-			return "", err
+	for _, name := range names {
+		if err := db.Exec("ALTER INDEX ? ON ? DISABLE", clause.Column{Name: name}, clause.Table{Name: stmt.Schema.Table}).Error; err != nil {
+			return err
 		}
 	}
-	return buf.String(), nil
+	return nil
+}
+
+// memRecorder records the statements in memory.
+type memRecorder struct {
+	stmts []string
+}
+
+func (r *memRecorder) Record(stmt string) {
+	r.stmts = append(r.stmts, stmt)
+}
+
+func (r *memRecorder) Statements() ([]string, bool) {
+	return r.stmts, true
+}
+
+// capturePool records the statements executed on the wrapped connection pool.
+type capturePool struct {
+	gorm.ConnPool
+	rec Recorder
+}
+
+func (p *capturePool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	p.rec.Record(query)
+	return p.ConnPool.ExecContext(ctx, query, args...)
 }
 
-func (l *Loader) directives(w io.Writer, cm *migrator) error {
+// recorder returns the recorder of the statements of Load.
+func (l *Loader) recorder() Recorder {
+	if l.rec != nil {
+		return l.rec
+	}
+	return sessionRecorder(l.sessionKey)
+}
+
+func (l *Loader) directives(w io.Writer, cm *migrator, stmts []string) error {
+	var file []string
+	// Concurrent index builds cannot run in a transaction.
+	if slices.ContainsFunc(stmts, concurrentIndex.MatchString) {
+		file = append(file, "-- atlas:txmode none")
+	}
 	if len(l.modelPos) > 0 {
 		pos := map[string]string{}
 		for m, p := range l.modelPos {
@@ -259,12 +1300,12 @@ func (l *Loader) directives(w io.Writer, cm *migrator) error {
 			pos[fmt.Sprintf("%s[type=%s]", cm.resourceName(m), t)] = p
 		}
 		for _, r := range slices.Sorted(maps.Keys(pos)) {
-			if _, err := fmt.Fprintln(w, "-- atlas:pos", r, pos[r]); err != nil {
-				return err
-			}
+			file = append(file, fmt.Sprintf("-- atlas:pos %s %s", r, pos[r]))
 		}
+	}
+	if len(file) > 0 {
 		// Add another new line to separate the file directives from the statements.
-		if _, err := fmt.Fprintln(w); err != nil {
+		if _, err := fmt.Fprintln(w, strings.Join(file, "\n")+"\n"); err != nil {
 			return err
 		}
 	}
@@ -274,10 +1315,12 @@ func (l *Loader) directives(w io.Writer, cm *migrator) error {
 type migrator struct {
 	gormig.Migrator
 	dialectMigrator gorm.Migrator
+	strictRefs      bool
 }
 
 type dialector struct {
 	gorm.Dialector
+	strictRefs bool
 }
 
 // Migrator returns a new gorm.Migrator, which can be used to extend the default migrator,
@@ -291,6 +1334,7 @@ func (d dialector) Migrator(db *gorm.DB) gorm.Migrator {
 			},
 		},
 		dialectMigrator: d.Dialector.Migrator(db),
+		strictRefs:      d.strictRefs,
 	}
 }
 
@@ -301,6 +1345,7 @@ func (m *migrator) HasTable(dst any) bool {
 
 // CreateConstraints detects constraints on the given model and creates them using `m.dialectMigrator`.
 func (m *migrator) CreateConstraints(models []any) error {
+	fkIndexes, refIndexes := make(map[string]bool), make(map[string]bool)
 	for _, model := range m.ReorderModels(models, true) {
 		err := m.Migrator.RunWithValue(model, func(stmt *gorm.Statement) error {
 
@@ -320,6 +1365,14 @@ func (m *migrator) CreateConstraints(models []any) error {
 				}
 				if constraint := rel.ParseConstraint(); constraint != nil &&
 					constraint.Schema == stmt.Schema {
+					if err := m.createReferencedIndex(constraint, refIndexes); err != nil {
+						return err
+					}
+					if m.Dialector.Name() == "mysql" {
+						if err := m.createForeignKeyIndex(model, stmt, constraint, fkIndexes); err != nil {
+							return err
+						}
+					}
 					if err := m.dialectMigrator.CreateConstraint(model, constraint.Name); err != nil {
 						return err
 					}
@@ -334,6 +1387,127 @@ func (m *migrator) CreateConstraints(models []any) error {
 	return nil
 }
 
+// createForeignKeyIndex creates an index for the columns of the given foreign key, unless they are
+// already the leftmost columns of another index. Otherwise, MySQL creates such an index implicitly,
+// named after the constraint, which differs from the name expected by Atlas.
+func (m *migrator) createForeignKeyIndex(model any, stmt *gorm.Statement, c *schema.Constraint, created map[string]bool) error {
+	columns := make([]string, len(c.ForeignKeys))
+	for i, f := range c.ForeignKeys {
+		columns[i] = f.DBName
+	}
+	tx, value, err := migrationTarget(m.DB, model)
+	if err != nil {
+		return err
+	}
+	ts := &gorm.Statement{DB: tx}
+	if err := ts.ParseWithSpecialTableName(value, tx.Statement.Table); err != nil {
+		return err
+	}
+	covered := hasPrefix(ts.Schema.PrimaryFieldDBNames, columns)
+	for _, idx := range ts.Schema.ParseIndexes() {
+		names := make([]string, 0, len(idx.Fields))
+		for _, f := range idx.Fields {
+			if f.Field == nil {
+				break
+			}
+			names = append(names, f.DBName)
+		}
+		covered = covered || hasPrefix(names, columns)
+	}
+	name := m.DB.NamingStrategy.IndexName(stmt.Table, strings.Join(columns, "_"))
+	if covered || created[stmt.Table+"."+name] {
+		return nil
+	}
+	created[stmt.Table+"."+name] = true
+	cols := make([]any, len(columns))
+	for i, col := range columns {
+		cols[i] = clause.Column{Name: col}
+	}
+	return m.DB.Exec("CREATE INDEX ? ON ? ?", clause.Column{Name: name}, m.CurrentTable(stmt), cols).Error
+}
+
+// createReferencedIndex creates a unique index for the columns referenced by the given foreign key,
+// unless they are the primary key or unique, as the referenced columns of a foreign key must be unique.
+// If auto-creation is disabled (see WithoutReferencedUniqueIndexes), an error is returned instead.
+func (m *migrator) createReferencedIndex(c *schema.Constraint, created map[string]bool) error {
+	columns := make([]string, len(c.References))
+	for i, f := range c.References {
+		columns[i] = f.DBName
+	}
+	model := reflect.New(c.ReferenceSchema.ModelType).Interface()
+	tx, value, err := migrationTarget(m.DB, model)
+	if err != nil {
+		return err
+	}
+	ts := &gorm.Statement{DB: tx}
+	if err := ts.ParseWithSpecialTableName(value, tx.Statement.Table); err != nil {
+		return err
+	}
+	covered := sameColumns(ts.Schema.PrimaryFieldDBNames, columns)
+	if f := ts.Schema.LookUpField(columns[0]); len(columns) == 1 && f != nil && f.Unique {
+		covered = true
+	}
+	// Names of the indexes and constraints of the table, that the synthesized index must not reuse.
+	names := make(map[string]bool)
+	for _, idx := range ts.Schema.ParseIndexes() {
+		names[idx.Name] = true
+		if idx.Class != "UNIQUE" || idx.Where != "" {
+			continue
+		}
+		names := make([]string, 0, len(idx.Fields))
+		for _, f := range idx.Fields {
+			if f.Field != nil {
+				names = append(names, f.DBName)
+			}
+		}
+		covered = covered || sameColumns(names, columns)
+	}
+	cs, err := uniqueConstraints(m.DB, model)
+	if err != nil {
+		return err
+	}
+	for _, uc := range cs {
+		names[uc.name] = true
+		covered = covered || sameColumns(uc.columns, columns)
+	}
+	table := c.ReferenceSchema.Table
+	// The index is named as a unique constraint, as the index name of the columns
+	// is the default name of a regular index on them, e.g. of an `index` tag.
+	name := m.DB.NamingStrategy.UniqueName(table, strings.Join(columns, "_"))
+	switch {
+	case covered || created[table+"."+name]:
+		return nil
+	case m.strictRefs:
+		return fmt.Errorf("foreign key %q references columns (%s) of %s that are neither a primary key nor unique", c.Name, strings.Join(columns, ", "), table)
+	case names[name]:
+		return fmt.Errorf("foreign key %q references columns (%s) of %s that are not unique, and the name %q of their unique index is already taken", c.Name, strings.Join(columns, ", "), table, name)
+	}
+	created[table+"."+name] = true
+	cols := make([]any, len(columns))
+	for i, col := range columns {
+		cols[i] = clause.Column{Name: col}
+	}
+	return m.DB.Exec("CREATE UNIQUE INDEX ? ON ? ?", clause.Column{Name: name}, clause.Table{Name: table}, cols).Error
+}
+
+// sameColumns reports whether the given column sets are equal, regardless of their order.
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, c := range b {
+		if !slices.Contains(a, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasPrefix reports whether the given columns are the leftmost columns of the index.
+func hasPrefix(index, columns []string) bool {
+	return len(index) >= len(columns) && slices.Equal(index[:len(columns)], columns)
+}
+
 // setupJoinTables helps to determine custom join tables present in the model list and sets them up.
 func (m *migrator) setupJoinTables(models ...any) error {
 	var dbNameModelMap = make(map[string]any)
@@ -448,6 +1622,400 @@ func (m *migrator) CreateTriggers(models []any) error {
 	return nil
 }
 
+var (
+	indexStmt = regexp.MustCompile(`^CREATE (?:UNIQUE |FULLTEXT |SPATIAL )?INDEX `)
+	indexDef  = regexp.MustCompile(`^(?:UNIQUE |FULLTEXT |SPATIAL )?INDEX `)
+	indexName = regexp.MustCompile(`INDEX (?:IF NOT EXISTS )?(\S+)`)
+	checkDef  = regexp.MustCompile(`^CONSTRAINT (\S+) CHECK `)
+)
+
+// sortIndexes sorts the indexes created by the given statements by their names. That is,
+// consecutive CREATE INDEX statements, and index and check definitions inlined in CREATE TABLE.
+func sortIndexes(stmts []string) {
+	byName := func(a, b string) int {
+		return strings.Compare(indexName.FindStringSubmatch(a)[1], indexName.FindStringSubmatch(b)[1])
+	}
+	for i := 0; i < len(stmts); i++ {
+		switch {
+		case indexStmt.MatchString(stmts[i]):
+			j := i + 1
+			for j < len(stmts) && indexStmt.MatchString(stmts[j]) {
+				j++
+			}
+			slices.SortStableFunc(stmts[i:j], byName)
+			i = j - 1
+		case strings.HasPrefix(stmts[i], "CREATE TABLE "):
+			start, end := strings.IndexByte(stmts[i], '('), strings.LastIndexByte(stmts[i], ')')
+			if start == -1 || end < start {
+				continue
+			}
+			defs := splitDefs(stmts[i][start+1 : end])
+			sortDefs(defs, indexDef, byName)
+			// GORM creates the checks of a table in map order as well.
+			sortDefs(defs, checkDef, func(a, b string) int {
+				return strings.Compare(checkDef.FindStringSubmatch(a)[1], checkDef.FindStringSubmatch(b)[1])
+			})
+			stmts[i] = stmts[i][:start+1] + strings.Join(defs, ",") + stmts[i][end:]
+		}
+	}
+}
+
+// sortDefs sorts the definitions matching the given pattern in place, keeping the positions they occupy.
+func sortDefs(defs []string, pattern *regexp.Regexp, cmp func(a, b string) int) {
+	var pos []int
+	var matched []string
+	for k, d := range defs {
+		if pattern.MatchString(d) {
+			pos, matched = append(pos, k), append(matched, d)
+		}
+	}
+	slices.SortStableFunc(matched, cmp)
+	for k, p := range pos {
+		defs[p] = matched[k]
+	}
+}
+
+// inline sets the start value of the auto-increment column in the CREATE TABLE statement
+// of its table, on MySQL and SQL Server.
+func (a *autoIncrement) inline(db *gorm.DB, stmts []string) {
+	for i, stmt := range stmts {
+		if !strings.HasPrefix(stmt, "CREATE TABLE") {
+			continue
+		}
+		switch db.Dialector.Name() {
+		case "mysql":
+			stmts[i] = fmt.Sprintf("%s AUTO_INCREMENT=%d", stmt, a.start)
+		case "sqlserver":
+			stmts[i] = strings.Replace(stmt, "IDENTITY(1,1)", fmt.Sprintf("IDENTITY(%d,1)", a.start), 1)
+		}
+		return
+	}
+}
+
+// concurrently builds the given indexes created by the statements concurrently.
+func concurrently(db *gorm.DB, stmts []string, names []string) {
+	for i, stmt := range stmts {
+		m := createIndex.FindStringSubmatch(stmt)
+		if m == nil || !slices.ContainsFunc(names, func(n string) bool { return db.Statement.Quote(n) == m[1] }) {
+			continue
+		}
+		stmts[i] = strings.Replace(stmt, "INDEX ", "INDEX CONCURRENTLY ", 1)
+	}
+}
+
+// replaceIndexes returns the given statements with the given indexes dropped, if they exist, right
+// before the statements that create them. Indexes built concurrently are dropped concurrently (see dropStmts).
+func (l *Loader) replaceIndexes(db *gorm.DB, stmts []string, names []string) []string {
+	replaced := make([]string, 0, len(stmts))
+	for _, stmt := range stmts {
+		m := createIndex.FindStringSubmatch(stmt)
+		if drops := l.dropStmts(stmt); m != nil && len(drops) > 0 && slices.ContainsFunc(names, func(n string) bool { return db.Statement.Quote(n) == m[1] }) {
+			replaced = append(replaced, drops[0])
+		}
+		replaced = append(replaced, stmt)
+	}
+	return replaced
+}
+
+// restart returns the statement restarting the sequence backing the auto-increment column at
+// its start value on PostgreSQL. The sequence is looked up by PostgreSQL, as the names of the
+// sequences of serial and identity columns are truncated like any other identifier.
+func (a *autoIncrement) restart(db *gorm.DB) (string, error) {
+	if a == nil || db.Dialector.Name() != "postgres" {
+		return "", nil
+	}
+	table, err := sqlLiteral("postgres", reflect.ValueOf(db.Statement.Quote(clause.Table{Name: a.table})))
+	if err != nil {
+		return "", err
+	}
+	column, err := sqlLiteral("postgres", reflect.ValueOf(a.column))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), %d, false)", table, column, a.start), nil
+}
+
+// storageParam matches the names and values of storage parameters, e.g. "toast.autovacuum_enabled".
+var storageParam = regexp.MustCompile(`^\w+(\.\w+)*$`)
+
+// tableStorage appends the WITH clause of the given storage parameters to the CREATE TABLE statement.
+func tableStorage(stmts []string, params map[string]string) error {
+	kvs := make([]string, 0, len(params))
+	for _, k := range slices.Sorted(maps.Keys(params)) {
+		if !storageParam.MatchString(k) || !storageParam.MatchString(params[k]) {
+			return fmt.Errorf("invalid storage parameter %s=%s", k, params[k])
+		}
+		kvs = append(kvs, k+"="+params[k])
+	}
+	for i, stmt := range stmts {
+		if strings.HasPrefix(stmt, "CREATE TABLE") {
+			stmts[i] = stmt + " WITH (" + strings.Join(kvs, ",") + ")"
+			return nil
+		}
+	}
+	return nil
+}
+
+// defaultTablespace sets the given tablespace on the CREATE TABLE and CREATE INDEX
+// statements that do not set their own.
+func defaultTablespace(db *gorm.DB, stmts []string, name string) {
+	clause := " TABLESPACE " + db.Statement.Quote(name)
+	for i, stmt := range stmts {
+		switch {
+		case strings.Contains(stmt, " TABLESPACE "):
+		case strings.HasPrefix(stmt, "CREATE TABLE "):
+			stmts[i] = stmt + clause
+		case strings.HasPrefix(stmt, "CREATE ") && strings.Contains(stmt, " INDEX "):
+			// The tablespace of an index precedes its predicate.
+			if j := strings.Index(stmt, " WHERE "); j != -1 {
+				stmts[i] = stmt[:j] + clause + stmt[j:]
+			} else {
+				stmts[i] = stmt + clause
+			}
+		}
+	}
+}
+
+// inlineConstraints appends the table checks and the inline unique constraints to the CREATE TABLE
+// statement of their table.
+func inlineConstraints(db *gorm.DB, stmts []string, cs []uniqueConstraint, checks []tableCheck) {
+	for i, stmt := range stmts {
+		if !strings.HasPrefix(stmt, "CREATE TABLE") || !strings.HasSuffix(stmt, ")") {
+			continue
+		}
+		for _, c := range checks {
+			stmt = stmt[:len(stmt)-1] + "," + c.def(db) + ")"
+		}
+		for _, c := range cs {
+			if c.style == InlineConstraint {
+				stmt = stmt[:len(stmt)-1] + "," + c.def(db) + ")"
+			}
+		}
+		stmts[i] = stmt
+		return
+	}
+}
+
+// splitDefs splits the body of a CREATE TABLE statement into its
+// column and constraint definitions, ignoring nested or quoted commas.
+func splitDefs(body string) []string {
+	var (
+		defs  []string
+		depth int
+		quote rune
+		last  int
+	)
+	for i, r := range body {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			defs = append(defs, body[last:i])
+			last = i + 1
+		}
+	}
+	return append(defs, body[last:])
+}
+
+// isMariaDB reports whether the MySQL dialector of the session is connected to MariaDB.
+func isMariaDB(db *gorm.DB) bool {
+	d := db.Dialector
+	for {
+		switch w := d.(type) {
+		case tableDialector:
+			d = w.Dialector
+		case dialector:
+			d = w.Dialector
+		case *mysql.Dialector:
+			return strings.Contains(w.ServerVersion, "MariaDB")
+		default:
+			return false
+		}
+	}
+}
+
+// knownOpClasses returns the operator classes known to the session (see WithKnownOpClasses).
+func knownOpClasses(db *gorm.DB) map[string]OpClassInfo {
+	if d, ok := tablesOf(db); ok && d.opClasses != nil {
+		return d.opClasses
+	}
+	return builtinOpClasses
+}
+
+// tablesOf returns the dialector of the given session that creates the tables, if
+// the session is a loader session. Otherwise, the defaults of the loader apply.
+func tablesOf(db *gorm.DB) (tableDialector, bool) {
+	d := db.Dialector
+	for {
+		switch w := d.(type) {
+		case tableDialector:
+			return w, true
+		case dialector:
+			d = w.Dialector
+		default:
+			return tableDialector{tableOptions: &tableOptions{}}, false
+		}
+	}
+}
+
+// tableOptions are the options of the loader that change how the tables and their indexes are
+// created. They are shared by the loader and the dialector of its sessions (see tablesOf).
+type tableOptions struct {
+	canonicalTypes    bool
+	explicitSort      bool
+	opClasses         map[string]OpClassInfo
+	dropIncludes      bool
+	strictLengths     bool
+	hashNames         bool
+	strictOpClasses   bool
+	strictColumns     bool
+	predicateWarnings bool
+	strictPredicates  bool
+	strictNulls       bool
+	strictMethods     bool
+}
+
+// tableDialector creates the tables with explicit NOT NULL primary keys, as only some
+// dialects imply it, and optionally with the canonical column types (see WithCanonicalTypes).
+type tableDialector struct {
+	gorm.Dialector
+	*tableOptions
+}
+
+func (d tableDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return tableMigrator{Migrator: d.Dialector.Migrator(db), dialector: d.Dialector, canonicalTypes: d.canonicalTypes}
+}
+
+type tableMigrator struct {
+	gorm.Migrator
+	dialector      gorm.Dialector
+	canonicalTypes bool
+}
+
+func (m tableMigrator) FullDataTypeOf(f *schema.Field) clause.Expr {
+	if t, ok := canonicalType(m.dialector.Name(), f); ok && m.canonicalTypes {
+		c := *f
+		c.DataType = t
+		f = &c
+	}
+	if f.PrimaryKey && !f.NotNull {
+		c := *f
+		c.NotNull = true
+		f = &c
+	}
+	if _, ok := f.TagSettings[constraintKey]; ok {
+		// Dialects size the string columns of indexes (e.g. varchar(191) instead of longtext
+		// on MySQL), hence the columns of unique constraints are typed as indexed columns.
+		c := *f
+		c.TagSettings = maps.Clone(f.TagSettings)
+		c.TagSettings["INDEX"] = "INDEX"
+		f = &c
+	}
+	expr := m.Migrator.FullDataTypeOf(f)
+	if chk := f.TagSettings[columnCheck]; chk != "" {
+		expr.SQL += " CHECK (" + chk + ")"
+	}
+	return expr
+}
+
+// canonicalType returns the canonical type of the given field, if it has one.
+func canonicalType(dialect string, f *schema.Field) (schema.DataType, bool) {
+	if f.DataType != schema.String || f.PrimaryKey || f.HasDefaultValue {
+		return "", false
+	}
+	for _, k := range []string{"SIZE", "TYPE", "INDEX", "UNIQUEINDEX", "UNIQUE", constraintKey} {
+		if _, ok := f.TagSettings[k]; ok {
+			return "", false
+		}
+	}
+	if dialect == "sqlserver" {
+		return "nvarchar(MAX)", true
+	}
+	return "text", true
+}
+
+func (m tableMigrator) DataTypeOf(f *schema.Field) string {
+	if dm, ok := m.Migrator.(interface{ DataTypeOf(*schema.Field) string }); ok {
+		return dm.DataTypeOf(f)
+	}
+	return m.dialector.DataTypeOf(f)
+}
+
+func (m tableMigrator) BuildIndexOptions(opts []schema.IndexOption, stmt *gorm.Statement) []any {
+	return m.Migrator.(gormig.BuildIndexOptionsInterface).BuildIndexOptions(opts, stmt)
+}
+
+func (m tableMigrator) ReorderModels(values []any, autoAdd bool) []any {
+	return m.Migrator.(interface{ ReorderModels([]any, bool) []any }).ReorderModels(values, autoAdd)
+}
+
+// schemaNamer qualifies the table names of the underlying Namer with a schema.
+type schemaNamer struct {
+	schema.Namer
+	schema string
+}
+
+func (n schemaNamer) TableName(table string) string {
+	return n.qualify(n.Namer.TableName(table))
+}
+
+func (n schemaNamer) JoinTableName(table string) string {
+	return n.qualify(n.Namer.JoinTableName(table))
+}
+
+func (n schemaNamer) RelationshipFKName(rel schema.Relationship) string {
+	s := *rel.Schema
+	s.Table = n.unqualify(s.Table)
+	rel.Schema = &s
+	return n.Namer.RelationshipFKName(rel)
+}
+
+func (n schemaNamer) CheckerName(table, column string) string {
+	return n.Namer.CheckerName(n.unqualify(table), column)
+}
+
+func (n schemaNamer) IndexName(table, column string) string {
+	return n.Namer.IndexName(n.unqualify(table), column)
+}
+
+func (n schemaNamer) UniqueName(table, column string) string {
+	return n.Namer.UniqueName(n.unqualify(table), column)
+}
+
+func (n schemaNamer) qualify(table string) string {
+	if strings.Contains(table, ".") {
+		return table
+	}
+	return n.schema + "." + table
+}
+
+func (n schemaNamer) unqualify(table string) string {
+	return strings.TrimPrefix(table, n.schema+".")
+}
+
+// foldNamer lowercases the generated index names, as PostgreSQL folds unquoted identifiers
+// to lowercase. Hence, generated names match the names of unquoted references, e.g. in migrations
+// written by hand, while names set explicitly are kept as-is, as they are always quoted.
+type foldNamer struct {
+	schema.Namer
+}
+
+func (n foldNamer) IndexName(table, column string) string {
+	return strings.ToLower(n.Namer.IndexName(table, column))
+}
+
+func (n foldNamer) UniqueName(table, column string) string {
+	return strings.ToLower(n.Namer.UniqueName(table, column))
+}
+
 func indirect(t reflect.Type) reflect.Type {
 	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
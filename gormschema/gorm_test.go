@@ -1,8 +1,13 @@
 package gormschema_test
 
 import (
+	"context"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"ariga.io/atlas-provider-gorm/gormschema"
 	ckmodels "ariga.io/atlas-provider-gorm/internal/testdata/circularfks"
@@ -10,7 +15,10 @@ import (
 	"ariga.io/atlas-provider-gorm/internal/testdata/models"
 	"ariga.io/atlas/sdk/recordriver"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func TestSQLiteConfig(t *testing.T) {
@@ -136,6 +144,23 @@ func TestMySQLConfig(t *testing.T) {
 	requireEqualContent(t, sql, "testdata/mysql_custom_join_table.sql") // position of tables should not matter
 }
 
+func TestMariaDBConfig(t *testing.T) {
+	resetSession()
+	l := gormschema.New("mariadb")
+	sql, err := l.Load(
+		models.WorkingAgedUsers{},
+		ckmodels.Location{},
+		ckmodels.Event{},
+		models.UserPetHistory{},
+		models.User{},
+		models.Pet{},
+		models.TopPetOwner{},
+	)
+	require.NoError(t, err)
+	requireEqualContent(t, sql, "testdata/mariadb_default.sql")
+	resetSession()
+}
+
 func TestSQLServerConfig(t *testing.T) {
 	resetSession()
 	l := gormschema.New("sqlserver", gormschema.WithStmtDelimiter("\nGO"))
@@ -174,3 +199,765 @@ func requireEqualContent(t *testing.T, actual, fileName string) {
 	require.NoError(t, err)
 	require.Equal(t, string(buf), actual)
 }
+
+// requireVanilla asserts that migrating the models with AutoMigrateModel records exactly the
+// statements recorded by the plain db.AutoMigrate of their vanilla counterparts. Hence, it
+// exposes any divergence introduced by the cloned types of the models.
+func requireVanilla(t *testing.T, models, vanilla []any) {
+	t.Helper()
+	migrate := func(key string, f func(*gorm.DB, any) error, models []any) []string {
+		db, err := gorm.Open(postgres.New(postgres.Config{DriverName: "recordriver", DSN: key}), &gorm.Config{})
+		require.NoError(t, err)
+		for _, m := range models {
+			require.NoError(t, f(db, m))
+		}
+		sess, ok := recordriver.Session(key)
+		require.True(t, ok)
+		defer func() { sess.Statements = nil }()
+		stmts := make([]string, len(sess.Statements))
+		for i, stmt := range sess.Statements {
+			stmts[i] = sortConstraints(stmt)
+		}
+		return stmts
+	}
+	require.Equal(t,
+		migrate("vanilla", func(db *gorm.DB, m any) error { return db.AutoMigrate(m) }, vanilla),
+		migrate("clone", gormschema.AutoMigrateModel, models),
+	)
+}
+
+// sortConstraints sorts the constraints of the given CREATE TABLE statement, as GORM
+// creates the foreign keys of join tables in random order.
+func sortConstraints(stmt string) string {
+	parts := strings.Split(strings.TrimSuffix(stmt, ")"), ",CONSTRAINT ")
+	if len(parts) < 3 {
+		return stmt
+	}
+	slices.Sort(parts[1:])
+	return strings.Join(parts, ",CONSTRAINT ") + ")"
+}
+
+type TaggedLabel struct {
+	ID   uint
+	Name string `gorm:"index:idx_labels_name,unique"`
+}
+
+func (TaggedLabel) TableName() string { return "labels" }
+
+type Label struct {
+	ID   uint
+	Name string
+}
+
+func (Label) TableName() string { return "labels" }
+
+func (Label) Indexes() []gormschema.IndexDefinition[Label] {
+	return []gormschema.IndexDefinition[Label]{
+		{
+			Name:    "idx_labels_name",
+			Columns: []gormschema.Col[Label]{gormschema.Field(func(m *Label) any { return &m.Name })},
+			Unique:  true,
+		},
+	}
+}
+
+func TestVanillaComparison(t *testing.T) {
+	plain := []any{&models.User{}, &models.Pet{}}
+	requireVanilla(t, plain, plain)
+	requireVanilla(t, []any{&Label{}}, []any{&TaggedLabel{}})
+}
+
+type Author struct {
+	ID             uint
+	Handle         string
+	LatestBookISBN string
+	LatestBook     *Book `gorm:"foreignKey:LatestBookISBN;references:ISBN"`
+}
+
+func (Author) Indexes() []gormschema.IndexDefinition[Author] {
+	return []gormschema.IndexDefinition[Author]{
+		{
+			Name:    "idx_authors_handle",
+			Columns: []gormschema.Col[Author]{gormschema.Field(func(m *Author) any { return &m.Handle })},
+			Unique:  true,
+		},
+	}
+}
+
+type Book struct {
+	ID           uint
+	ISBN         string
+	AuthorHandle string
+	Author       *Author `gorm:"foreignKey:AuthorHandle;references:Handle"`
+}
+
+func (Book) Indexes() []gormschema.IndexDefinition[Book] {
+	return []gormschema.IndexDefinition[Book]{
+		{
+			Name:    "idx_books_isbn",
+			Columns: []gormschema.Col[Book]{gormschema.Field(func(m *Book) any { return &m.ISBN })},
+			Unique:  true,
+		},
+	}
+}
+
+func TestCircularReferencedUniqueIndexes(t *testing.T) {
+	for _, dialect := range []string{"mysql", "postgres", "sqlserver"} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Author{}, Book{})
+			require.NoError(t, err)
+			for _, idx := range []string{"idx_authors_handle", "idx_books_isbn"} {
+				i := strings.Index(sql, idx)
+				require.NotEqual(t, -1, i, "index %q is missing", idx)
+				for _, fk := range []string{"fk_authors_latest_book", "fk_books_author"} {
+					j := strings.Index(sql, fk)
+					require.NotEqual(t, -1, j, "foreign key %q is missing", fk)
+					require.Less(t, i, j, "index %q must be created before foreign key %q", idx, fk)
+				}
+			}
+			resetSession()
+		})
+	}
+}
+
+type recordLogger struct {
+	logger.Interface
+	stmts []string
+}
+
+func (r *recordLogger) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+func (r *recordLogger) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	stmt, _ := fc()
+	r.stmts = append(r.stmts, stmt)
+}
+
+func TestWithLogger(t *testing.T) {
+	resetSession()
+	rl := &recordLogger{Interface: logger.Discard}
+	sql, err := gormschema.New("postgres", gormschema.WithLogger(rl)).Load(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	require.Contains(t, rl.stmts, `CREATE TABLE "users" ("id" bigserial NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"age" bigint,PRIMARY KEY ("id"))`)
+	require.Contains(t, rl.stmts, `ALTER TABLE "pets" ADD CONSTRAINT "fk_users_pets" FOREIGN KEY ("user_id") REFERENCES "users"("id")`)
+	for _, stmt := range rl.stmts {
+		require.Contains(t, sql, stmt+";\n")
+	}
+	resetSession()
+}
+
+func TestMySQLForeignKeyIndexes(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("mysql").Load(models.Pet{}, models.User{})
+	require.NoError(t, err)
+	idx := strings.Index(sql, "CREATE INDEX `idx_pets_user_id` ON `pets` (`user_id`);\n")
+	fk := strings.Index(sql, "ALTER TABLE `pets` ADD CONSTRAINT `fk_users_pets` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`);\n")
+	require.NotEqual(t, -1, idx)
+	require.Less(t, idx, fk)
+	// Foreign keys covered by the leftmost columns of the primary key or another index are skipped.
+	require.NotContains(t, sql, "idx_user_hobbies_hobby_id")
+	resetSession()
+	sql, err = gormschema.New("mysql").Load(ckmodels.Location{}, ckmodels.Event{})
+	require.NoError(t, err)
+	require.NotContains(t, sql, "CREATE INDEX")
+	resetSession()
+}
+
+func TestWithSchema(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithSchema("app")).Load(models.Pet{}, models.User{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE TABLE "app"."users" (`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "app"."users" ("deleted_at");`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_pets_deleted_at" ON "app"."pets" ("deleted_at");`)
+	require.Contains(t, sql, `ALTER TABLE "app"."pets" ADD CONSTRAINT "fk_users_pets" FOREIGN KEY ("user_id") REFERENCES "app"."users"("id");`)
+	require.NotContains(t, sql, `"idx_app_`)
+	resetSession()
+}
+
+type Workspace struct {
+	ID   uint
+	Name string `gorm:"index"`
+}
+
+func (Workspace) TableName() string { return "workspaces" }
+
+type Board struct {
+	ID          uint
+	WorkspaceID uint
+	Workspace   Workspace
+}
+
+func TestWithSchemaTableName(t *testing.T) {
+	for dialect, expected := range map[string][]string{
+		"postgres": {
+			`CREATE EXTENSION IF NOT EXISTS "pg_trgm" SCHEMA "app";`,
+			`CREATE TABLE "app"."workspaces" (`,
+			`CREATE INDEX IF NOT EXISTS "idx_workspaces_name" ON "app"."workspaces" ("name");`,
+			`CREATE TABLE "app"."boards" (`,
+			`ALTER TABLE "app"."boards" ADD CONSTRAINT "fk_boards_workspace" FOREIGN KEY ("workspace_id") REFERENCES "app"."workspaces"("id");`,
+		},
+		"sqlserver": {
+			`CREATE TABLE "app"."workspaces" (`,
+			`CREATE INDEX "idx_workspaces_name" ON "app"."workspaces"("name");`,
+			`ALTER TABLE "app"."boards" ADD CONSTRAINT "fk_boards_workspace" FOREIGN KEY ("workspace_id") REFERENCES "app"."workspaces"("id");`,
+		},
+		"mysql": {
+			"CREATE TABLE `app`.`workspaces` (",
+			"ALTER TABLE `app`.`boards` ADD CONSTRAINT `fk_boards_workspace` FOREIGN KEY (`workspace_id`) REFERENCES `app`.`workspaces`(`id`);",
+		},
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			opts := []gormschema.Option{gormschema.WithSchema("app")}
+			if dialect == "postgres" {
+				opts = append(opts, gormschema.WithExtensions("pg_trgm"))
+			}
+			sql, err := gormschema.New(dialect, opts...).Load(Board{}, Workspace{})
+			require.NoError(t, err)
+			for _, s := range expected {
+				require.Contains(t, sql, s)
+			}
+			resetSession()
+		})
+	}
+	// Tables are not qualified without a schema.
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Board{}, Workspace{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `REFERENCES "workspaces"("id");`)
+	require.NotContains(t, sql, `"app"`)
+	resetSession()
+
+	// The tables of the sessions of the caller are not qualified.
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	pool, err := db.DB()
+	require.NoError(t, err)
+	pool.SetMaxOpenConns(1)
+	require.NoError(t, db.Exec("ATTACH DATABASE ':memory:' AS app").Error)
+	sql, err = gormschema.New("sqlite", gormschema.WithSchema("app")).LoadWithDB(db, InlineSeat{})
+	require.NoError(t, err)
+	require.Contains(t, sql, "CREATE TABLE `app`.`seats` (")
+	dry := db.Session(&gorm.Session{DryRun: true})
+	require.Equal(t, "SELECT * FROM `seats`", dry.Find(&[]InlineSeat{}).Statement.SQL.String())
+	db, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	resetSession()
+	sql, err = gormschema.New("postgres", gormschema.WithConfig(db.Config), gormschema.WithSchema("app")).Load(Board{}, Workspace{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE TABLE "app"."workspaces" (`)
+	dry = db.Session(&gorm.Session{DryRun: true})
+	require.Equal(t, "SELECT * FROM `workspaces`", dry.Find(&[]Workspace{}).Statement.SQL.String())
+	require.Equal(t, "SELECT * FROM `boards`", dry.Find(&[]Board{}).Statement.SQL.String())
+	resetSession()
+}
+
+type Note struct {
+	ID   uint
+	Body string
+	Code string `gorm:"size:32"`
+}
+
+func TestWithCanonicalTypes(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres":  `CREATE TABLE "notes" ("id" bigserial NOT NULL,"body" text,"code" varchar(32),PRIMARY KEY ("id"));`,
+		"mysql":     "CREATE TABLE `notes` (`id` bigint unsigned AUTO_INCREMENT NOT NULL,`body` text,`code` varchar(32),PRIMARY KEY (`id`));",
+		"sqlite":    "CREATE TABLE `notes` (`id` integer PRIMARY KEY AUTOINCREMENT NOT NULL,`body` text,`code` text);",
+		"sqlserver": `CREATE TABLE "notes" ("id" bigint IDENTITY(1,1) NOT NULL,"body" nvarchar(MAX),"code" nvarchar(32),PRIMARY KEY ("id"));`,
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect, gormschema.WithCanonicalTypes()).Load(Note{})
+			require.NoError(t, err)
+			require.Equal(t, expected+"\n", sql)
+			resetSession()
+		})
+	}
+	resetSession()
+	sql, err := gormschema.New("mysql").Load(Note{})
+	require.NoError(t, err)
+	require.Contains(t, sql, "`body` longtext")
+	resetSession()
+}
+
+func TestLoadWithDB(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	pool, err := db.DB()
+	require.NoError(t, err)
+	pool.SetMaxOpenConns(1)
+	sql, err := gormschema.New("sqlite").LoadWithDB(db,
+		models.WorkingAgedUsers{},
+		models.Pet{},
+		models.UserPetHistory{},
+		ckmodels.Event{},
+		ckmodels.Location{},
+		models.TopPetOwner{},
+	)
+	require.NoError(t, err)
+	requireEqualContent(t, sql, "testdata/sqlite_default.sql")
+	// The statements were executed on the given database.
+	require.True(t, db.Migrator().HasTable("pets"))
+	require.True(t, db.Migrator().HasIndex("events", "idx_events_location_id"))
+	_, err = gormschema.New("postgres").LoadWithDB(db, models.Pet{})
+	require.EqualError(t, err, `session dialect "sqlite" does not match loader dialect "postgres"`)
+
+	// Statements that are rewritten by the loader, e.g. inlined constraints,
+	// are executed the way they are returned.
+	sql, err = gormschema.New("sqlite").LoadWithDB(db, InlineSeat{})
+	require.NoError(t, err)
+	resetSession()
+	expected, err := gormschema.New("sqlite").Load(InlineSeat{})
+	require.NoError(t, err)
+	require.Equal(t, expected, sql)
+	var created string
+	require.NoError(t, db.Raw("SELECT sql FROM sqlite_master WHERE name = 'seats'").Scan(&created).Error)
+	require.Equal(t, sql, created+";\n")
+	require.Contains(t, created, "CONSTRAINT `uq_seats_position` UNIQUE")
+	resetSession()
+}
+
+type Membership struct {
+	GroupID string `gorm:"primaryKey"`
+	UserID  string `gorm:"primaryKey"`
+	Role    string
+}
+
+func TestPrimaryKeyNotNull(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres": `CREATE TABLE "memberships" ("group_id" text NOT NULL,"user_id" text NOT NULL,"role" text,PRIMARY KEY ("group_id","user_id"));`,
+		"sqlite":   "CREATE TABLE `memberships` (`group_id` text NOT NULL,`user_id` text NOT NULL,`role` text,PRIMARY KEY (`group_id`,`user_id`));",
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Membership{})
+			require.NoError(t, err)
+			require.Equal(t, expected+"\n", sql)
+			resetSession()
+		})
+	}
+}
+
+func TestWithSessionKey(t *testing.T) {
+	var (
+		wg     sync.WaitGroup
+		sqls   [2]string
+		errs   [2]error
+		models = []any{Membership{}, Note{}}
+	)
+	for i, key := range []string{"gorm-memberships", "gorm-notes"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sqls[i], errs[i] = gormschema.New("postgres", gormschema.WithSessionKey(key)).Load(models[i])
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.Equal(t, `CREATE TABLE "memberships" ("group_id" text NOT NULL,"user_id" text NOT NULL,"role" text,PRIMARY KEY ("group_id","user_id"));`+"\n", sqls[0])
+	require.Equal(t, `CREATE TABLE "notes" ("id" bigserial NOT NULL,"body" text,"code" varchar(32),PRIMARY KEY ("id"));`+"\n", sqls[1])
+}
+
+type ProfileV1 struct {
+	ID   uint
+	Name string
+}
+
+func (ProfileV1) TableName() string { return "profiles" }
+
+type ProfileV2 struct {
+	ID   uint
+	Name string
+	Bio  string
+	Age  int `gorm:"not null;default:0"`
+}
+
+func (ProfileV2) TableName() string { return "profiles" }
+
+func TestWithAddColumnIfNotExists(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithAddColumnIfNotExists(ProfileV1{})).Load(ProfileV2{}, Note{})
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE "profiles" ADD COLUMN IF NOT EXISTS "bio" text;
+ALTER TABLE "profiles" ADD COLUMN IF NOT EXISTS "age" bigint NOT NULL DEFAULT 0;
+CREATE TABLE "notes" ("id" bigserial NOT NULL,"body" text,"code" varchar(32),PRIMARY KEY ("id"));
+`, sql)
+	resetSession()
+	_, err = gormschema.New("sqlite", gormschema.WithAddColumnIfNotExists(ProfileV1{})).Load(ProfileV2{})
+	require.EqualError(t, err, "ADD COLUMN IF NOT EXISTS is not supported by sqlite")
+	resetSession()
+}
+
+type Warehouse struct {
+	ID   uint
+	Code string `gorm:"size:16"`
+}
+
+type Shelf struct {
+	ID            uint
+	WarehouseCode string     `gorm:"size:16"`
+	Warehouse     *Warehouse `gorm:"foreignKey:WarehouseCode;references:Code"`
+}
+
+func TestReferencedUniqueIndexes(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Warehouse{}, Shelf{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE UNIQUE INDEX "uni_warehouses_code" ON "warehouses" ("code");
+ALTER TABLE "shelves" ADD CONSTRAINT "fk_shelves_warehouse" FOREIGN KEY ("warehouse_code") REFERENCES "warehouses"("code");`)
+	resetSession()
+	_, err = gormschema.New("postgres", gormschema.WithoutReferencedUniqueIndexes()).Load(Warehouse{}, Shelf{})
+	require.EqualError(t, err, `foreign key "fk_shelves_warehouse" references columns (code) of warehouses that are neither a primary key nor unique`)
+	// Regular indexes of the referenced columns do not share their name with the unique index.
+	resetSession()
+	sql, err = gormschema.New("postgres").Load(Depot{}, Bin{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_depots_code" ON "depots" ("code");`)
+	require.Contains(t, sql, `CREATE UNIQUE INDEX "uni_depots_code" ON "depots" ("code");`)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(Yard{}, Stall{})
+	require.EqualError(t, err, `foreign key "fk_stalls_yard" references columns (code) of yards that are not unique, and the name "uni_yards_code" of their unique index is already taken`)
+	resetSession()
+}
+
+type Depot struct {
+	ID   uint
+	Code string `gorm:"size:16;index"`
+}
+
+type Bin struct {
+	ID        uint
+	DepotCode string `gorm:"size:16"`
+	Depot     *Depot `gorm:"foreignKey:DepotCode;references:Code"`
+}
+
+type Yard struct {
+	ID   uint
+	Code string `gorm:"size:16;index:uni_yards_code"`
+}
+
+type Stall struct {
+	ID       uint
+	YardCode string `gorm:"size:16"`
+	Yard     *Yard  `gorm:"foreignKey:YardCode;references:Code"`
+}
+
+func TestLoadDown(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithExtensions("citext")).LoadDown(models.User{}, models.Pet{}, models.WorkingAgedUsers{})
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE "pets" DROP CONSTRAINT IF EXISTS "fk_users_pets";
+ALTER TABLE "user_hobbies" DROP CONSTRAINT IF EXISTS "fk_user_hobbies_user";
+ALTER TABLE "user_hobbies" DROP CONSTRAINT IF EXISTS "fk_user_hobbies_hobby";
+DROP VIEW IF EXISTS working_aged_users;
+DROP INDEX IF EXISTS "idx_pets_deleted_at";
+DROP TABLE IF EXISTS "pets";
+DROP TABLE IF EXISTS "user_hobbies";
+DROP TABLE IF EXISTS "hobbies";
+DROP INDEX IF EXISTS "idx_users_deleted_at";
+DROP TABLE IF EXISTS "users";
+DROP EXTENSION IF EXISTS "citext";
+`, sql)
+	resetSession()
+	sql, err = gormschema.New("mysql").LoadDown(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	require.Equal(t, "SET FOREIGN_KEY_CHECKS = 0;\nDROP TABLE IF EXISTS `pets`;\nDROP TABLE IF EXISTS `user_hobbies`;\n"+
+		"DROP TABLE IF EXISTS `hobbies`;\nDROP TABLE IF EXISTS `users`;\nSET FOREIGN_KEY_CHECKS = 1;\n", sql)
+	resetSession()
+	sql, err = gormschema.New("mariadb").LoadDown(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	require.Contains(t, sql, "ALTER TABLE `pets` DROP FOREIGN KEY IF EXISTS `fk_users_pets`;\nDROP INDEX IF EXISTS `idx_pets_user_id` ON `pets`;\n")
+	resetSession()
+	sql, err = gormschema.New("sqlserver").LoadDown(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `DROP INDEX IF EXISTS "idx_users_deleted_at" ON "users";`+"\n"+`DROP TABLE IF EXISTS "users";`)
+	resetSession()
+	sql, err = gormschema.New("sqlite").LoadDown(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	require.Contains(t, sql, "DROP INDEX IF EXISTS `idx_users_deleted_at`;\nDROP TABLE IF EXISTS `users`;")
+	resetSession()
+}
+
+func TestWithTableStorage(t *testing.T) {
+	storage := gormschema.WithTableStorage(map[any]map[string]string{
+		&models.Pet{}: {"fillfactor": "90", "autovacuum_vacuum_scale_factor": "0.05"},
+	})
+	resetSession()
+	sql, err := gormschema.New("postgres", storage).Load(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `PRIMARY KEY ("id")) WITH (autovacuum_vacuum_scale_factor=0.05,fillfactor=90);
+CREATE INDEX IF NOT EXISTS "idx_pets_deleted_at"`)
+	require.Equal(t, 1, strings.Count(sql, "WITH ("))
+	resetSession()
+	sql, err = gormschema.New("mysql", storage).Load(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	require.NotContains(t, sql, "WITH (")
+	resetSession()
+}
+
+func TestDependencyGraph(t *testing.T) {
+	graph, err := gormschema.New("postgres").DependencyGraph(ckmodels.Event{}, ckmodels.Location{})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"events":    {"locations"},
+		"locations": {"events"},
+	}, graph)
+	graph, err = gormschema.New("mysql").DependencyGraph(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"users":        {},
+		"pets":         {"users"},
+		"user_hobbies": {"hobbies", "users"},
+	}, graph)
+	graph, err = gormschema.New("postgres", gormschema.WithSchema("app")).DependencyGraph(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"app.users":        {},
+		"app.pets":         {"app.users"},
+		"app.user_hobbies": {"app.hobbies", "app.users"},
+	}, graph)
+	// Models that cannot be parsed are reported.
+	_, err = gormschema.New("postgres", gormschema.WithLogger(logger.Discard)).DependencyGraph(struct{ Tags []string }{})
+	require.EqualError(t, err, "unsupported data type: &[]")
+}
+
+// stmtRecorder records the statements of a loader in memory.
+type stmtRecorder struct {
+	stmts []string
+}
+
+func (r *stmtRecorder) Record(stmt string) { r.stmts = append(r.stmts, stmt) }
+
+func (r *stmtRecorder) Statements() ([]string, bool) { return r.stmts, true }
+
+// copyRecorder is a stmtRecorder that returns a copy of its statements.
+type copyRecorder struct{ stmtRecorder }
+
+func (r *copyRecorder) Statements() ([]string, bool) { return slices.Clone(r.stmts), true }
+
+func TestWithRecorder(t *testing.T) {
+	var (
+		wg     sync.WaitGroup
+		recs   = [2]*stmtRecorder{{}, {}}
+		sqls   [2]string
+		errs   [2]error
+		models = []any{Membership{}, Note{}}
+	)
+	// Loaders share the default session, but not their statements.
+	for i := range recs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sqls[i], errs[i] = gormschema.New("postgres", gormschema.WithRecorder(recs[i])).Load(models[i])
+		}()
+	}
+	wg.Wait()
+	for i, rec := range recs {
+		require.NoError(t, errs[i])
+		require.Len(t, rec.stmts, 1)
+		require.Equal(t, rec.stmts[0]+";\n", sqls[i])
+	}
+	require.Contains(t, sqls[0], `CREATE TABLE "memberships"`)
+	require.Contains(t, sqls[1], `CREATE TABLE "notes"`)
+	resetSession()
+}
+
+type ShardedOrder struct {
+	ID      uint
+	Carrier string
+}
+
+func (ShardedOrder) AutoIncrementStart() int64 { return 1000 }
+
+func TestAutoIncrementStart(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"mysql":     "CREATE TABLE `sharded_orders` (`id` bigint unsigned AUTO_INCREMENT NOT NULL,`carrier` longtext,PRIMARY KEY (`id`)) AUTO_INCREMENT=1000;\n",
+		"sqlserver": `CREATE TABLE "sharded_orders" ("id" bigint IDENTITY(1000,1) NOT NULL,"carrier" nvarchar(MAX),PRIMARY KEY ("id"));` + "\n",
+		"postgres":  `CREATE TABLE "sharded_orders" ("id" bigserial NOT NULL,"carrier" text,PRIMARY KEY ("id"));` + "\n" + `SELECT setval(pg_get_serial_sequence('"sharded_orders"', 'id'), 1000, false);` + "\n",
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(ShardedOrder{})
+			require.NoError(t, err)
+			require.Equal(t, expected, sql)
+		})
+	}
+	// The sequence is looked up in the schema of the table.
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithSchema("app")).Load(ShardedOrder{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `SELECT setval(pg_get_serial_sequence('"app"."sharded_orders"', 'id'), 1000, false);`)
+	resetSession()
+	_, err = gormschema.New("sqlite").Load(ShardedOrder{})
+	require.EqualError(t, err, "auto-increment start of ShardedOrder: not supported by sqlite")
+	resetSession()
+}
+
+func TestWithLockTimeout(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres": `SET lock_timeout = '5s';
+CREATE TABLE "notes" ("id" bigserial NOT NULL,"body" text,"code" varchar(32),PRIMARY KEY ("id"));
+RESET lock_timeout;
+`,
+		"mysql":  "SET SESSION lock_wait_timeout = 5;\nCREATE TABLE `notes` (`id` bigint unsigned AUTO_INCREMENT NOT NULL,`body` longtext,`code` varchar(32),PRIMARY KEY (`id`));\nSET SESSION lock_wait_timeout = DEFAULT;\n",
+		"sqlite": "CREATE TABLE `notes` (`id` integer PRIMARY KEY AUTOINCREMENT NOT NULL,`body` text,`code` text);\n",
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect, gormschema.WithLockTimeout(5*time.Second)).Load(&Note{})
+			require.NoError(t, err)
+			require.Equal(t, expected, sql)
+		})
+	}
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithLockTimeout(1500*time.Microsecond)).Load(&Note{})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(sql, "SET lock_timeout = '2ms';\n"), sql)
+	resetSession()
+}
+
+func TestWithStatementTerminator(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("sqlserver",
+		gormschema.WithStmtDelimiter("\nGO"),
+		gormschema.WithStatementTerminator(";"),
+	).Load(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	stmts := strings.Split(strings.TrimSuffix(sql, "\n"), "\nGO\n")
+	require.Greater(t, len(stmts), 1)
+	for _, stmt := range stmts {
+		require.True(t, strings.HasSuffix(stmt, ";"), stmt)
+		require.False(t, strings.HasSuffix(stmt, ";;"), stmt)
+	}
+	resetSession()
+	sql, err = gormschema.New("postgres", gormschema.WithStatementTerminator(";")).Load(&Note{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "notes" ("id" bigserial NOT NULL,"body" text,"code" varchar(32),PRIMARY KEY ("id"));`+"\n", sql)
+	resetSession()
+}
+
+func TestStmtDelimiterDialects(t *testing.T) {
+	for _, dialect := range []string{"postgres", "mysql", "mariadb", "sqlite", "sqlserver"} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			var opts []gormschema.Option
+			if dialect == "postgres" {
+				opts = append(opts, gormschema.WithExtensions("pg_trgm", "citext"))
+			}
+			want, err := gormschema.New(dialect, opts...).Load(Note{}, Board{}, Workspace{})
+			require.NoError(t, err)
+			resetSession()
+			sql, err := gormschema.New(dialect, append(opts, gormschema.WithStmtDelimiter("\n-- split --"))...).Load(Note{}, Board{}, Workspace{})
+			require.NoError(t, err)
+			require.False(t, strings.HasSuffix(sql, "-- split --\n"))
+			// Splitting on the delimiter yields the statements, including the extensions.
+			stmts := strings.Split(strings.TrimSuffix(sql, "\n"), "\n-- split --\n")
+			require.Equal(t, strings.Split(strings.TrimSuffix(want, ";\n"), ";\n"), stmts)
+			require.Greater(t, len(stmts), 3)
+			for _, stmt := range stmts {
+				require.NotEmpty(t, strings.TrimSpace(stmt))
+				require.NotContains(t, stmt, "-- split --")
+			}
+			resetSession()
+		})
+	}
+}
+
+// chunkWriter records the chunks written to it.
+type chunkWriter struct {
+	chunks []string
+	err    error
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.chunks = append(w.chunks, string(p))
+	return len(p), nil
+}
+
+func TestLoadTo(t *testing.T) {
+	resetSession()
+	l := gormschema.New("sqlserver", gormschema.WithStmtDelimiter("\nGO"))
+	want, err := l.Load(models.User{}, models.Pet{})
+	require.NoError(t, err)
+	resetSession()
+	w := &chunkWriter{}
+	require.NoError(t, l.LoadTo(w, models.User{}, models.Pet{}))
+	// The statements are written one by one, each followed by the delimiter, except the last one.
+	require.Equal(t, want, strings.Join(w.chunks, ""))
+	require.Greater(t, len(w.chunks), 1)
+	for _, c := range w.chunks[:len(w.chunks)-1] {
+		require.True(t, strings.HasSuffix(c, "\nGO\n"), c)
+	}
+	require.False(t, strings.HasSuffix(w.chunks[len(w.chunks)-1], "GO\n"))
+	resetSession()
+	w = &chunkWriter{err: os.ErrClosed}
+	require.ErrorIs(t, l.LoadTo(w, models.User{}), os.ErrClosed)
+	resetSession()
+}
+
+type OldPayment struct {
+	ID     uint
+	Amount int
+	Ref    string
+}
+
+func (OldPayment) TableName() string { return "payments" }
+
+type Payment struct {
+	ID     uint
+	Amount string `gorm:"type:numeric(12,2)"`
+	Ref    string
+}
+
+func (Payment) TypeCasts() map[string]string {
+	return map[string]string{"Amount": "amount::numeric(12,2)"}
+}
+
+func TestAlterColumnTypes(t *testing.T) {
+	sql, err := gormschema.New("postgres").AlterColumnTypes([]any{OldPayment{}}, []any{Payment{}})
+	require.NoError(t, err)
+	require.Equal(t, `ALTER TABLE "payments" ALTER COLUMN "amount" TYPE numeric(12,2) USING amount::numeric(12,2);`+"\n", sql)
+
+	sql, err = gormschema.New("postgres").AlterColumnTypes([]any{OldPayment{}}, []any{OldPayment{}})
+	require.NoError(t, err)
+	require.Empty(t, sql)
+
+	_, err = gormschema.New("mysql").AlterColumnTypes([]any{OldPayment{}}, []any{Payment{}})
+	require.EqualError(t, err, `type cast of "Amount": USING expressions are supported only by PostgreSQL`)
+	_, err = gormschema.New("sqlite").AlterColumnTypes([]any{OldPayment{}}, []any{Payment{}})
+	require.EqualError(t, err, "sqlite does not support altering column types")
+
+	// The definitions of the columns are kept, e.g. their nullability and defaults.
+	for dialect, expected := range map[string]string{
+		"postgres": `ALTER TABLE "tallies" ALTER COLUMN "hits" TYPE bigint;` + "\n" + `ALTER TABLE "tallies" ALTER COLUMN "label" TYPE varchar(64);`,
+		"mysql": "ALTER TABLE `tallies` MODIFY COLUMN `id` bigint unsigned AUTO_INCREMENT NOT NULL;\n" +
+			"ALTER TABLE `tallies` MODIFY COLUMN `hits` bigint NOT NULL DEFAULT 1;\n" +
+			"ALTER TABLE `tallies` MODIFY COLUMN `label` varchar(64);",
+		"sqlserver": `ALTER TABLE "tallies" ALTER COLUMN "hits" bigint NOT NULL;` + "\n" + `ALTER TABLE "tallies" ALTER COLUMN "label" nvarchar(64) NULL;`,
+	} {
+		sql, err := gormschema.New(dialect).AlterColumnTypes([]any{OldTally{}}, []any{Tally{}})
+		require.NoError(t, err)
+		require.Equal(t, expected+"\n", sql, dialect)
+	}
+}
+
+type OldTally struct {
+	ID    uint32
+	Hits  int16  `gorm:"not null;default:1"`
+	Label string `gorm:"size:32"`
+}
+
+func (OldTally) TableName() string { return "tallies" }
+
+type Tally struct {
+	ID    uint
+	Hits  int64  `gorm:"not null;default:1"`
+	Label string `gorm:"size:64"`
+}
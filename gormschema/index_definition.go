@@ -1,10 +1,18 @@
 package gormschema
 
 import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"gorm.io/gorm"
@@ -15,187 +23,3096 @@ import (
 
 // Column selector + per-column options.
 type Col[T any] struct {
-	Sel   func(*T) any // MUST return a *pointer* to the struct field (e.g., `&m.TenantID`)
-	Sort  string       // "", "asc", "desc"
-	Nulls string       // "", "first", "last" (used as `sort:desc nulls last`)
+	Sel           func(*T) any      // MUST return a *pointer* to the struct field (e.g., `&m.TenantID`)
+	FieldName     string            // "", or the name of the struct field, used if Sel is unset (see FieldByName)
+	Sort          string            // "", "asc", "desc"
+	Nulls         string            // "", "first", "last" (used as `sort:desc nulls last`)
+	OpClass       string            // "", or an operator class, e.g. "gin_trgm_ops"
+	OpClassParams map[string]string // parameters of OpClass, e.g. {"siglen": "256"} (see ClassWithOptions)
+	Collation     string            // "", or the collation of the column, e.g. "C" or "NOCASE" (see Collate)
+	Length        int               // 0, or the prefix length of the column (MySQL only, see Length)
+	Expr          string            // "", or an SQL expression indexed instead of the Sel column (see TSVector)
+	Refs          []func(*T) any    // columns referenced by Expr as {1}, {2}, ..., replaced by their quoted names
+	err           string            // error of the builder of the column, reported by the loader
+	jsonRefs      bool              // whether the Refs must be json or jsonb columns (see JSONPath)
+	tsvector      bool              // whether Expr is a text search vector (see TSVector)
 }
 
 func Field[T any](sel func(*T) any) Col[T] { return Col[T]{Sel: sel} }
-func Asc[T any](c Col[T]) Col[T]           { c.Sort = "asc"; return c }
-func Desc[T any](c Col[T]) Col[T]          { c.Sort = "desc"; return c }
-func NullsFirst[T any](c Col[T]) Col[T]    { c.Nulls = "first"; return c }
-func NullsLast[T any](c Col[T]) Col[T]     { c.Nulls = "last"; return c }
+
+// FieldByName returns a column selecting the struct field of the given name, e.g.
+// FieldByName[User]("TenantID"), as an alternative to Field for generated code. The
+// promoted fields of embedded structs are selected by their own names.
+func FieldByName[T any](name string) Col[T] { return Col[T]{FieldName: name} }
+
+func Asc[T any](c Col[T]) Col[T]        { c.Sort = "asc"; return c }
+func Desc[T any](c Col[T]) Col[T]       { c.Sort = "desc"; return c }
+func NullsFirst[T any](c Col[T]) Col[T] { c.Nulls = "first"; return c }
+func NullsLast[T any](c Col[T]) Col[T]  { c.Nulls = "last"; return c }
+
+// Class sets the operator class of the column, e.g. Class(Field(...), "gin_trgm_ops").
+// Operator classes of other schemas are qualified with their schema, e.g. "app.custom_ops".
+func Class[T any](c Col[T], opclass string) Col[T] { c.OpClass = opclass; return c }
+
+// ClassWithOptions sets the operator class of the column with its parameters, e.g.
+// ClassWithOptions(Field(...), "gist_trgm_ops", map[string]string{"siglen": "256"}), that are
+// rendered after the class in the order of their names: gist_trgm_ops (siglen=256).
+func ClassWithOptions[T any](c Col[T], opclass string, params map[string]string) Col[T] {
+	c.OpClass, c.OpClassParams = opclass, params
+	return c
+}
+
+// Collate sets the collation of the column, e.g. Collate(Field(...), "C"). On SQLite, only its
+// built-in collations are supported: BINARY, NOCASE and RTRIM, and SQL Server does not support
+// collations of index columns.
+func Collate[T any](c Col[T], collation string) Col[T] { c.Collation = collation; return c }
+
+// Length sets the prefix length of the column, e.g. Length(Field(...), 255), as required by MySQL
+// for TEXT and BLOB columns. Other dialects do not support prefix lengths, and ignore them unless
+// the loader is strict (see WithStrictPrefixLengths).
+func Length[T any](c Col[T], n int) Col[T] { c.Length = n; return c }
+
+// Expr returns a column indexing the given SQL expression, e.g. Expr[T]("lower(email)::text").
+// Columns are referenced either as-is, or as the placeholders {1}, {2}, ... of the given selectors,
+// which are replaced by their quoted column names. Like other columns, expressions can have an
+// operator class and ordering, e.g. Class(Expr[T]("lower(email)::text"), "text_pattern_ops").
+func Expr[T any](expr string, refs ...func(*T) any) Col[T] { return Col[T]{Expr: expr, Refs: refs} }
+
+// TSVector returns a column indexing the text search vector of the selected columns using the
+// given text search configuration, e.g. to_tsvector('english', "body"). Multiple columns are
+// concatenated, with NULL values treated as empty strings. It is supported by PostgreSQL only.
+func TSVector[T any](config string, cols ...func(*T) any) Col[T] {
+	c := Col[T]{Refs: cols, tsvector: true}
+	switch {
+	case !tsConfigName.MatchString(config):
+		c.err = fmt.Sprintf("invalid text search configuration %q", config)
+	case len(cols) == 0:
+		c.err = "tsvector requires at least one column"
+	}
+	c.Expr = tsvectorExpr(config, len(cols))
+	return c
+}
+
+// tsvectorExpr returns the text search vector expression of n columns, referenced as {1}, {2}, ...
+func tsvectorExpr(config string, n int) string {
+	doc := "{1}"
+	if n > 1 {
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = fmt.Sprintf("coalesce({%d}, '')", i+1)
+		}
+		doc = strings.Join(parts, " || ' ' || ")
+	}
+	return fmt.Sprintf("to_tsvector('%s', %s)", config, doc)
+}
+
+// Weighted is a column of a weighted text search vector (see TSVectorWeighted).
+type Weighted[T any] struct {
+	Sel    func(*T) any // MUST return a *pointer* to the struct field
+	Weight string       // "A", "B", "C" or "D", from the most to the least relevant
+}
+
+// Weight returns the selected column weighted by the given weight, e.g. Weight(sel, "A").
+func Weight[T any](sel func(*T) any, weight string) Weighted[T] {
+	return Weighted[T]{Sel: sel, Weight: weight}
+}
+
+// TSVectorWeighted is like TSVector, but weights the text search vector of each column before
+// concatenating them, e.g. setweight(to_tsvector('english', "title"), 'A') || ... Hence, matches
+// in columns of higher weights rank higher. It is supported by PostgreSQL only.
+func TSVectorWeighted[T any](config string, cols ...Weighted[T]) Col[T] {
+	c := Col[T]{tsvector: true}
+	parts := make([]string, len(cols))
+	for i, w := range cols {
+		weight := strings.ToUpper(strings.TrimSpace(w.Weight))
+		if len(weight) != 1 || weight[0] < 'A' || weight[0] > 'D' {
+			c.err = fmt.Sprintf("invalid weight %q of column %d, expected A, B, C or D", w.Weight, i+1)
+		}
+		c.Refs = append(c.Refs, w.Sel)
+		parts[i] = fmt.Sprintf("setweight(to_tsvector('%s', coalesce({%d}, '')), '%s')", config, i+1, weight)
+	}
+	switch {
+	case !tsConfigName.MatchString(config):
+		c.err = fmt.Sprintf("invalid text search configuration %q", config)
+	case len(cols) == 0:
+		c.err = "tsvector requires at least one column"
+	}
+	c.Expr = strings.Join(parts, " || ")
+	return c
+}
+
+// JSONPath returns a column indexing the value at the given path of the selected json or jsonb
+// column, cast to the given type, e.g. (("data"->>'age')::integer). Keys of nested paths are
+// separated by dots, e.g. "address.city", and an empty asType indexes the value as text. Hence,
+// multiple paths of a column can be indexed separately. It is supported by PostgreSQL only.
+func JSONPath[T any](col func(*T) any, path string, asType string) Col[T] {
+	c := Col[T]{Refs: []func(*T) any{col}, jsonRefs: true}
+	keys := strings.Split(path, ".")
+	for _, k := range keys {
+		if !jsonKey.MatchString(k) {
+			c.err = fmt.Sprintf("invalid json path %q", path)
+		}
+	}
+	if asType != "" && !castType.MatchString(asType) {
+		c.err = fmt.Sprintf("invalid type %q", asType)
+	}
+	c.Expr = fmt.Sprintf("{1}->>'%s'", path)
+	if len(keys) > 1 {
+		c.Expr = fmt.Sprintf("{1}#>>'{%s}'", strings.Join(keys, ","))
+	}
+	if asType != "" {
+		c.Expr = "(" + c.Expr + ")::" + asType
+	}
+	return c
+}
+
+var (
+	// jsonKey matches the keys of json paths.
+	jsonKey = regexp.MustCompile(`^[\w-]+$`)
+	// castType matches the types that values can be cast to, e.g. "numeric(10,2)" or "text[]".
+	castType = regexp.MustCompile(`^[A-Za-z_][\w ]*(\(\d+(\s*,\s*\d+)?\))?(\[\])?$`)
+)
+
+// tsConfigName matches text search configuration names, optionally qualified
+// with their schema, e.g. "english" or "pg_catalog.simple".
+var tsConfigName = regexp.MustCompile(`^[A-Za-z_]\w*(\.[A-Za-z_]\w*)?$`)
+
+// Cond is a column predicate of a partial index (see WhereEq).
+type Cond[T any] struct {
+	Sel   func(*T) any // MUST return a *pointer* to the struct field
+	Value any          // string, bool, number or nil
+}
+
+// WhereEq returns a predicate comparing the selected column to value. The value is rendered
+// as a literal of the loader dialect: strings are quoted and escaped, booleans are rendered
+// as true/false on PostgreSQL and 1/0 elsewhere, and nil is rendered as "IS NULL".
+func WhereEq[T any](sel func(*T) any, value any) Cond[T] { return Cond[T]{Sel: sel, Value: value} }
+
+// TablePlaceholder can be used in the Name of an IndexDefinition, and is replaced by the name of
+// its table. Hence, the definitions of a base model embedded by multiple models, e.g. a shared
+// Indexes() method, create indexes of distinct names, e.g. "idx_{table}_tenant_id".
+const TablePlaceholder = "{table}"
 
 // IndexDefinition declares a composite (or single-column) index.
+//
+// The "fulltext" Type declares a full-text index, created as a FULLTEXT index on MySQL, and as a
+// gin index of the text search vector of its columns on PostgreSQL (see TSVector). Other dialects
+// do not support full-text indexes.
 type IndexDefinition[T any] struct {
 	Name    string
-	Columns []Col[T] // order => priority:1..N
+	Columns []Col[T] // order => priority:1..N; at least one, e.g. an Expr column for expression indexes
+	Include []Col[T] // non-key columns of a covering index (PostgreSQL and SQL Server only)
 	Unique  bool
-	Where   string // e.g. "deleted_at IS NULL"
+	Type    string          // access method of the whole index, e.g. "gin" (inferred from operator classes if unset)
+	Where   string          // e.g. "deleted_at IS NULL"; partial indexes are not supported by MySQL
+	Conds   []Cond[T]       // ANDed with Where, e.g. WhereEq(...)
+	Style   ConstraintStyle // "", or the placement of a UNIQUE constraint
+	Comment string          // comment of the UNIQUE constraint (PostgreSQL only)
+	// NullsNotDistinct treats NULLs as equal values of a Unique definition, hence rows whose
+	// columns are NULL collide, using NULLS NOT DISTINCT (PostgreSQL 15+). SQL Server implies it,
+	// and other dialects ignore it with a warning (see WithStrictNullsNotDistinct).
+	NullsNotDistinct bool
+	// Invisible indexes are maintained but ignored by the optimizer. They are created
+	// as INVISIBLE on MySQL and IGNORED on MariaDB, and not supported by other dialects.
+	Invisible bool
+	// Disabled creates the index disabled, using ALTER INDEX ... DISABLE right after it is created,
+	// e.g. for bulk loads, that rebuild it afterwards (SQL Server only, and ignored with a warning
+	// by other dialects).
+	Disabled bool
+	// Cluster marks the index as the clustering index of the table, which is physically
+	// ordered by it using CLUSTER after the index is created (PostgreSQL only). A table
+	// has at most one clustering index.
+	Cluster bool
+	// Concurrent builds the index without blocking writes to its table, using CREATE INDEX
+	// CONCURRENTLY (PostgreSQL only, and ignored with a warning by other dialects). As such
+	// statements cannot run in a transaction, the output disables the transaction of Atlas.
+	Concurrent bool
+	// Replace drops the index, if it exists, right before it is created, hence an index of the
+	// same name is replaced by the definition, e.g. while iterating on it in staging. Concurrent
+	// indexes are dropped concurrently on PostgreSQL. MySQL creates the indexes with their tables,
+	// hence it does not support replacing them.
+	Replace bool
+	// Tablespace is the tablespace of the index (PostgreSQL only), overriding
+	// the default tablespace of the loader (see WithDefaultTablespace).
+	Tablespace string
+	// With holds the storage parameters of the index, e.g. {"fillfactor": "70"}, rendered
+	// as its WITH clause in the order of their names (PostgreSQL and SQL Server only). The
+	// parameters must be supported by the access method of the index, e.g. pages_per_range
+	// by brin or gin_pending_list_limit by gin.
+	With map[string]string
+	// Workload sets the fillfactor of the index from the workload of its table, unless
+	// With sets it explicitly (PostgreSQL and SQL Server only).
+	Workload WorkloadHint
+}
+
+// WorkloadHint describes the read/write ratio of a table, that implies the fillfactor of its indexes.
+type WorkloadHint string
+
+const (
+	// ReadHeavy indexes are fully packed (fillfactor=100), as their pages are rarely split.
+	ReadHeavy WorkloadHint = "read_heavy"
+	// Balanced indexes leave some room for updates (fillfactor=90), the default of PostgreSQL.
+	Balanced WorkloadHint = "balanced"
+	// WriteHeavy indexes leave room for frequent inserts and updates (fillfactor=70).
+	WriteHeavy WorkloadHint = "write_heavy"
+)
+
+// workloadFillFactors are the fillfactors implied by the workload hints.
+var workloadFillFactors = map[WorkloadHint]string{
+	ReadHeavy:  "100",
+	Balanced:   "90",
+	WriteHeavy: "70",
+}
+
+// IndexBuilder builds an IndexDefinition fluently, e.g.:
+//
+//	NewIndex[User]("idx_users_email").
+//		Unique().
+//		Where("deleted_at IS NULL").
+//		Add(Field(func(u *User) any { return &u.Email })).
+//		Build()
+//
+// The built definition is identical to the equivalent struct literal.
+type IndexBuilder[T any] struct {
+	def IndexDefinition[T]
+}
+
+// NewIndex returns a builder of the index of the given name.
+func NewIndex[T any](name string) *IndexBuilder[T] {
+	return &IndexBuilder[T]{def: IndexDefinition[T]{Name: name}}
+}
+
+// Add appends the given columns to the key columns of the index.
+func (b *IndexBuilder[T]) Add(cols ...Col[T]) *IndexBuilder[T] {
+	b.def.Columns = append(b.def.Columns, cols...)
+	return b
+}
+
+// Include appends the given columns to the non-key columns of the index.
+func (b *IndexBuilder[T]) Include(cols ...Col[T]) *IndexBuilder[T] {
+	b.def.Include = append(b.def.Include, cols...)
+	return b
+}
+
+// Unique marks the index as unique.
+func (b *IndexBuilder[T]) Unique() *IndexBuilder[T] {
+	b.def.Unique = true
+	return b
+}
+
+// Type sets the access method of the index, e.g. "gin".
+func (b *IndexBuilder[T]) Type(typ string) *IndexBuilder[T] {
+	b.def.Type = typ
+	return b
+}
+
+// Where sets the predicate of a partial index, e.g. "deleted_at IS NULL".
+func (b *IndexBuilder[T]) Where(where string) *IndexBuilder[T] {
+	b.def.Where = where
+	return b
+}
+
+// Conds appends the given predicates, ANDed with Where, e.g. WhereEq(...).
+func (b *IndexBuilder[T]) Conds(conds ...Cond[T]) *IndexBuilder[T] {
+	b.def.Conds = append(b.def.Conds, conds...)
+	return b
+}
+
+// Style sets the placement of a UNIQUE constraint.
+func (b *IndexBuilder[T]) Style(style ConstraintStyle) *IndexBuilder[T] {
+	b.def.Style = style
+	return b
+}
+
+// Comment sets the comment of a UNIQUE constraint.
+func (b *IndexBuilder[T]) Comment(comment string) *IndexBuilder[T] {
+	b.def.Comment = comment
+	return b
+}
+
+// NullsNotDistinct treats NULLs as equal values of a unique index.
+func (b *IndexBuilder[T]) NullsNotDistinct() *IndexBuilder[T] {
+	b.def.NullsNotDistinct = true
+	return b
+}
+
+// Invisible marks the index as ignored by the optimizer.
+func (b *IndexBuilder[T]) Invisible() *IndexBuilder[T] {
+	b.def.Invisible = true
+	return b
+}
+
+// Disabled creates the index disabled.
+func (b *IndexBuilder[T]) Disabled() *IndexBuilder[T] {
+	b.def.Disabled = true
+	return b
+}
+
+// Cluster marks the index as the clustering index of its table.
+func (b *IndexBuilder[T]) Cluster() *IndexBuilder[T] {
+	b.def.Cluster = true
+	return b
+}
+
+// Concurrent builds the index without blocking writes to its table.
+func (b *IndexBuilder[T]) Concurrent() *IndexBuilder[T] {
+	b.def.Concurrent = true
+	return b
+}
+
+// Replace drops the index, if it exists, right before it is created.
+func (b *IndexBuilder[T]) Replace() *IndexBuilder[T] {
+	b.def.Replace = true
+	return b
+}
+
+// Tablespace sets the tablespace of the index.
+func (b *IndexBuilder[T]) Tablespace(name string) *IndexBuilder[T] {
+	b.def.Tablespace = name
+	return b
+}
+
+// With sets a storage parameter of the index, e.g. With("fillfactor", "70").
+func (b *IndexBuilder[T]) With(param, value string) *IndexBuilder[T] {
+	if b.def.With == nil {
+		b.def.With = make(map[string]string)
+	}
+	b.def.With[param] = value
+	return b
+}
+
+// Workload sets the fillfactor of the index from the workload of its table.
+func (b *IndexBuilder[T]) Workload(hint WorkloadHint) *IndexBuilder[T] {
+	b.def.Workload = hint
+	return b
+}
+
+// Build returns the built definition. The builder can be reused, e.g. to build
+// similar definitions, as the definition does not share its slices and map with it.
+func (b *IndexBuilder[T]) Build() IndexDefinition[T] {
+	def := b.def
+	def.Columns = slices.Clone(def.Columns)
+	def.Include = slices.Clone(def.Include)
+	def.Conds = slices.Clone(def.Conds)
+	def.With = maps.Clone(def.With)
+	return def
+}
+
+// Check declares a table check constraint, which might reference multiple columns.
+type Check[T any] struct {
+	Name string   // "", or the name of the constraint (defaults to chk_<table>_<column> of its first column with a free name)
+	Expr string   // the check expression; columns are referenced as-is, or as {1}, {2}, ... of Cols
+	Cols []Col[T] // columns referenced by the placeholders of Expr, replaced by their quoted names
+}
+
+// CheckExpr returns a check constraint whose placeholders {1}, {2}, ... reference the given columns,
+// e.g. CheckExpr[T]("chk_events_range", "{1} < {2}", Field(...StartAt), Field(...EndAt)).
+func CheckExpr[T any](name, expr string, cols ...Col[T]) Check[T] {
+	return Check[T]{Name: name, Expr: expr, Cols: cols}
+}
+
+// RLSSpec describes the row-level security of a table, declared by the RLS() *RLSSpec method
+// of a model. For non-nil specs, the loader enables row-level security after creating the
+// table, and then creates its policies (PostgreSQL only).
+type RLSSpec struct {
+	Force    bool     // whether the policies also apply to the owner of the table
+	Policies []Policy // policies created after the table, in order
+}
+
+// Policy is a row-level security policy of a table.
+type Policy struct {
+	Name    string   // the name of the policy (required)
+	Command string   // "", "all", "select", "insert", "update" or "delete"
+	Roles   []string // roles the policy applies to, all roles (PUBLIC) if empty
+	Using   string   // "", or the expression filtering the existing rows
+	Check   string   // "", or the expression the new rows must satisfy (WITH CHECK)
+}
+
+// ConstraintStyle creates a Unique definition as a UNIQUE constraint instead of a unique index. Unlike
+// unique indexes, constraints can be referenced by the foreign keys of PostgreSQL and SQL Server, and
+// they cannot be partial or have expressions, sort orders or operator classes. MySQL and MariaDB back
+// the constraints with unique indexes of the same names, and SQLite with automatic indexes. The string
+// columns of constraints are typed as indexed columns, e.g. varchar(191) instead of longtext on MySQL.
+type ConstraintStyle string
+
+const (
+	// InlineConstraint creates the constraint within the CREATE TABLE statement.
+	InlineConstraint ConstraintStyle = "inline"
+	// AlterConstraint creates the constraint using an ALTER TABLE statement after all
+	// tables were created. It is not supported by SQLite, which requires inline constraints.
+	AlterConstraint ConstraintStyle = "alter"
+)
+
+// SoftDelete can be used as the Where of an IndexDefinition. The loader expands
+// it to the model's soft-delete predicate (e.g. "deleted_at IS NULL"), using the
+// column of its gorm.DeletedAt field.
+const SoftDelete = "@soft_delete"
+
+// AutoMigrateModel inspects 'model' for an Indexes() method.
+// If present, it uses those definitions to synthesize index tags on a
+// cloned runtime type, then runs AutoMigrate on that clone.
+// If not, it falls back to db.AutoMigrate(model).
+//
+// The definitions of embedded base models are merged with the ones of the model,
+// even if the Indexes() method of the model shadows the method of its bases.
+// Hence, the model and its bases must not declare indexes of the same name.
+//
+// Column-scoped check constraints can be declared the same way, using a
+// ColumnChecks() map[string]string method that maps a field name to its check
+// expression. These checks are created within the CREATE TABLE statement: inline
+// with their column on SQLite, which does not support adding them to an existing
+// table, and as table constraints on the other dialects.
+//
+// Checks spanning multiple columns can be declared using a Checks() []Check[T]
+// method. Their expressions reference columns either by name, or using the
+// placeholders of the selected columns (see CheckExpr). They are created as named
+// constraints within the CREATE TABLE statement, hence a column might be referenced
+// by any number of checks.
+//
+// Stored generated columns can be declared using a GeneratedColumns() map[string]string
+// method that maps a field name to the expression of its column. Their indexes are created
+// like any other index, after the column is created by the CREATE TABLE statement.
+//
+// Fields managed outside of GORM can be excluded from the table using an IgnoredColumns()
+// []string method that returns their names, even if they are not tagged with `gorm:"-"`.
+//
+// Column defaults can be declared using a Defaults() map[string]string method
+// that maps a field name to its default expression. The portable tokens @now,
+// @true, @false and @uuid are expanded to the expression of the loader dialect,
+// and other values are used as-is.
+func AutoMigrateModel(db *gorm.DB, model any) error {
+	tx, value, err := migrationTarget(db, model)
+	if err != nil {
+		return err
+	}
+	return tx.AutoMigrate(value)
+}
+
+// createModel creates the table of the given model the same way AutoMigrateModel
+// migrates it, but without pulling in its dependencies, as Load already orders them.
+func createModel(db *gorm.DB, model any) error {
+	tx, value, err := migrationTarget(db, model)
+	if err != nil {
+		return err
+	}
+	return tx.Migrator().CreateTable(value)
+}
+
+// redundantIndex is an index whose columns are a left-prefix of the columns of another index.
+type redundantIndex struct {
+	table, name, by string
+}
+
+// redundantIndexes returns the indexes of the model whose columns are a left-prefix of the columns
+// of another index of its table (see WithRedundantIndexWarnings). Unique, partial and expression
+// indexes are never reported, as other indexes do not replace them.
+func redundantIndexes(db *gorm.DB, model any) ([]redundantIndex, error) {
+	tx, value, err := migrationTarget(db, model)
+	if err != nil {
+		return nil, err
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(value); err != nil {
+		return nil, err
+	}
+	// Clones are migrated using the table of their model.
+	table := tx.Statement.Table
+	if table == "" {
+		table = stmt.Schema.Table
+	}
+	var (
+		names   []string
+		columns = map[string][]string{}
+		indexes = stmt.Schema.ParseIndexes()
+	)
+	for name, idx := range indexes {
+		cols := make([]string, 0, len(idx.Fields))
+		for _, f := range idx.Fields {
+			if f.Expression != "" {
+				cols = nil
+				break
+			}
+			cols = append(cols, f.DBName)
+		}
+		if len(cols) > 0 {
+			names = append(names, name)
+			columns[name] = cols
+		}
+	}
+	slices.Sort(names)
+	var rs []redundantIndex
+	for _, name := range names {
+		if idx := indexes[name]; idx.Class != "" || idx.Where != "" {
+			continue
+		}
+		for _, by := range names {
+			// Of indexes with the same columns, only the latter is reported.
+			longer := len(columns[by]) > len(columns[name]) || by < name
+			if by != name && longer && indexes[by].Where == "" && strings.EqualFold(indexes[by].Type, indexes[name].Type) && hasPrefix(columns[by], columns[name]) {
+				rs = append(rs, redundantIndex{table: table, name: name, by: by})
+				break
+			}
+		}
+	}
+	return rs, nil
+}
+
+// migrationTarget returns the value to migrate for the given model, and the session
+// to migrate it with. Models without any of the hooks above are returned as-is.
+func migrationTarget(db *gorm.DB, model any) (*gorm.DB, any, error) {
+	if model == nil {
+		return nil, nil, fmt.Errorf("nil model")
+	}
+
+	base := indirectType(reflect.TypeOf(model))
+	if base.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("model must be a struct or *struct, got %v", base.Kind())
+	}
+
+	recv := receiver(model)
+	defs, hasIndexes := indexDefinitions(recv)
+	checker, hasChecks := recv.Interface().(interface {
+		ColumnChecks() map[string]string
+	})
+	defaulter, hasDefaults := recv.Interface().(interface {
+		Defaults() map[string]string
+	})
+	generator, hasGenerated := recv.Interface().(interface {
+		GeneratedColumns() map[string]string
+	})
+	ignorer, hasIgnored := recv.Interface().(interface {
+		IgnoredColumns() []string
+	})
+	if !hasIndexes && !hasChecks && !hasDefaults && !hasGenerated && !hasIgnored {
+		// Nothing to synthesize -> regular migration
+		return db, model, nil
+	}
+
+	// Parse the original model to get its table name and columns, as
+	// the cloned type below has neither a name nor a TableName method.
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, nil, err
+	}
+
+	// Build field -> index-tag fragments from the returned definitions.
+	var fieldToIndexTags map[string][]string
+	if hasIndexes {
+		var err error
+		if fieldToIndexTags, err = collectIndexTagsFromIndexesValue(stmt, base, defs); err != nil {
+			return nil, nil, err
+		}
+	}
+	var fieldToCheck map[string]string
+	if hasChecks {
+		var err error
+		if fieldToCheck, err = collectColumnChecks(stmt.Schema, base, checker.ColumnChecks()); err != nil {
+			return nil, nil, err
+		}
+	}
+	var fieldToDefault map[string]string
+	if hasDefaults {
+		var err error
+		if fieldToDefault, err = collectDefaults(stmt, base, defaulter.Defaults()); err != nil {
+			return nil, nil, err
+		}
+	}
+	var fieldToType map[string]string
+	if hasGenerated {
+		var err error
+		if fieldToType, err = collectGeneratedColumns(stmt, base, generator.GeneratedColumns(), fieldToIndexTags); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var keys map[string]bool
+	if hasIndexes {
+		keys = constraintKeys(defs)
+	}
+
+	ignored := make(map[string]bool)
+	if hasIgnored {
+		for _, name := range ignorer.IgnoredColumns() {
+			if sf, ok := base.FieldByName(name); !ok || len(sf.Index) != 1 || sf.PkgPath != "" {
+				return nil, nil, fmt.Errorf("ignored column %q: not a top-level exported field of %s", name, stmt.Schema.Name)
+			}
+			ignored[name] = true
+		}
+	}
+
+	// Build cloned struct type with merged tags.
+	fields := make([]reflect.StructField, 0, base.NumField())
+	for _, sf := range flattenFields(base) {
+		// Keep only exported fields; GORM ignores unexported columns anyway.
+		if sf.PkgPath != "" || ignored[sf.Name] {
+			continue
+		}
+		newTag := sf.Tag
+		if hasIndexes {
+			newTag = mergeIndexIntoGormTag(newTag, fieldToIndexTags[sf.Name])
+		}
+		if chk, ok := fieldToCheck[sf.Name]; ok {
+			if db.Dialector.Name() == "sqlite" {
+				newTag = appendGormTag(newTag, columnCheck+":"+chk)
+			} else {
+				// A leading comma tells GORM the check is unnamed,
+				// even if the expression itself contains commas.
+				newTag = appendGormTag(newTag, "check:,"+chk)
+			}
+		}
+		if def, ok := fieldToDefault[sf.Name]; ok {
+			newTag = appendGormTag(newTag, "default:"+def)
+		}
+		if keys[sf.Name] {
+			newTag = appendGormTag(newTag, constraintKey)
+		}
+		if typ, ok := fieldToType[sf.Name]; ok {
+			// Generated columns are read-only.
+			newTag = appendGormTag(newTag, "type:"+typ, "->")
+		}
+		// Field types are kept as-is, hence custom data types (e.g., implementing
+		// GormDBDataTypeInterface) are mapped to the same columns as the original.
+		fields = append(fields, reflect.StructField{
+			Name:      sf.Name,
+			Type:      sf.Type,
+			Tag:       newTag,
+			Anonymous: sf.Anonymous,
+		})
+	}
+
+	dyn := reflect.StructOf(fields)
+	// Fields might be excluded from the clone (e.g., unexported or ignored ones),
+	// hence the columns of the indexes are checked against the clone itself.
+	for _, name := range slices.Sorted(maps.Keys(fieldToIndexTags)) {
+		if _, ok := dyn.FieldByName(name); !ok && len(fieldToIndexTags[name]) > 0 {
+			index, _, _ := strings.Cut(strings.TrimPrefix(fieldToIndexTags[name][0], "index:"), ",")
+			return nil, nil, fmt.Errorf("index %q: field %q is excluded from the migrated model %s", index, name, stmt.Schema.Name)
+		}
+	}
+	return db.Table(stmt.Schema.Table), reflect.New(dyn).Interface(), nil
+}
+
+// columnCheck carries the ColumnChecks() expression of a field on SQLite, where it is created
+// within the column definition (see FullDataTypeOf), e.g. "age integer CHECK (age >= 0)".
+// Other dialects create it as a table constraint, using the check tag of GORM.
+const columnCheck = "COLUMNCHECK"
+
+// constraintKey marks the fields of the columns of unique constraints, hence they are sized like
+// the columns of unique indexes, e.g. varchar(191) instead of longtext on MySQL (see FullDataTypeOf).
+const constraintKey = "CONSTRAINTKEY"
+
+// constraintKeys returns the names of the fields of the columns of the unique constraints of the
+// given definitions. Unresolved columns are skipped, as they are reported by uniqueConstraints.
+func constraintKeys(defs []reflect.Value) map[string]bool {
+	keys := make(map[string]bool)
+	for _, def := range defs {
+		def = reflect.Indirect(def)
+		if def.FieldByName("Style").String() == "" {
+			continue
+		}
+		cols := def.FieldByName("Columns")
+		for j := 0; j < cols.Len(); j++ {
+			if fname, err := columnField(reflect.Indirect(cols.Index(j))); err == nil {
+				keys[fname] = true
+			}
+		}
+	}
+	return keys
+}
+
+// flattenFields returns the fields of the given struct, with the fields of its embedded structs in
+// their place, as GORM maps them to the same columns. Hence, the promoted fields of embedded base
+// models get the tags of their definitions (e.g., Indexes() of the base), and embedded types with
+// methods, which reflect.StructOf does not support, are not embedded in the clone. Embedded structs
+// with a prefix keep their fields, and fields of the outer struct shadow the promoted ones.
+func flattenFields(t reflect.Type) []reflect.StructField {
+	declared := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		declared[t.Field(i).Name] = true
+	}
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !flattened(sf) {
+			fields = append(fields, sf)
+			continue
+		}
+		for _, ef := range flattenFields(indirectType(sf.Type)) {
+			if !declared[ef.Name] {
+				ef.Index = append([]int{i}, ef.Index...)
+				fields = append(fields, ef)
+			}
+		}
+	}
+	return fields
+}
+
+// flattened reports whether the given field is an embedded struct whose fields are promoted
+// to the embedding struct (see flattenFields).
+func flattened(sf reflect.StructField) bool {
+	et := indirectType(sf.Type)
+	tag := strings.ToUpper(sf.Tag.Get("gorm"))
+	return sf.Anonymous && sf.PkgPath == "" && et.Kind() == reflect.Struct && tag != "-" && !strings.Contains(tag, "EMBEDDEDPREFIX") &&
+		// Custom data types (e.g., time.Time-like structs) are columns, not embedded fields.
+		!reflect.PointerTo(et).Implements(scannerType)
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// receiver returns a pointer to the given model, to access its pointer-receiver methods.
+func receiver(model any) reflect.Value {
+	mv := reflect.ValueOf(model)
+	if mv.Kind() == reflect.Ptr {
+		return mv
+	}
+	// create addressable copy to access pointer-receiver methods
+	p := reflect.New(mv.Type())
+	p.Elem().Set(mv)
+	return p
+}
+
+// uniqueConstraint is a Unique definition created as a constraint (see ConstraintStyle).
+type uniqueConstraint struct {
+	table            string
+	name             string
+	columns          []string
+	style            ConstraintStyle
+	comment          string
+	nullsNotDistinct bool
+}
+
+// uniqueConstraints returns the Unique definitions of the model that are created as constraints.
+func uniqueConstraints(db *gorm.DB, model any) ([]uniqueConstraint, error) {
+	if model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+		return nil, nil
+	}
+	defs, ok := indexDefinitions(receiver(model))
+	if !ok {
+		return nil, nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	var cs []uniqueConstraint
+	for _, def := range defs {
+		def = reflect.Indirect(def)
+		styleF := def.FieldByName("Style")
+		if !styleF.IsValid() || styleF.String() == "" {
+			continue
+		}
+		c := uniqueConstraint{
+			table:   stmt.Schema.Table,
+			name:    tableName(def.FieldByName("Name").String(), stmt.Schema.Table),
+			style:   ConstraintStyle(styleF.String()),
+			comment: def.FieldByName("Comment").String(),
+		}
+		switch {
+		case c.style != InlineConstraint && c.style != AlterConstraint:
+			return nil, fmt.Errorf("constraint %q: unknown style %q", c.name, c.style)
+		case c.style == AlterConstraint && db.Dialector.Name() == "sqlite":
+			return nil, fmt.Errorf("constraint %q: sqlite supports only inline unique constraints", c.name)
+		case !def.FieldByName("Unique").Bool():
+			return nil, fmt.Errorf("constraint %q: style %q requires a Unique definition", c.name, c.style)
+		case strings.TrimSpace(def.FieldByName("Where").String()) != "" || def.FieldByName("Conds").Len() > 0:
+			return nil, fmt.Errorf("constraint %q: unique constraints cannot be partial", c.name)
+		}
+		nnd, err := nullsNotDistinct(stmt, c.name, def)
+		if err != nil {
+			return nil, err
+		}
+		c.nullsNotDistinct = nnd
+		colsF := def.FieldByName("Columns")
+		for j := 0; j < colsF.Len(); j++ {
+			col := reflect.Indirect(colsF.Index(j))
+			if col.FieldByName("Sort").String() != "" || col.FieldByName("OpClass").String() != "" || col.FieldByName("OpClassParams").Len() > 0 {
+				return nil, fmt.Errorf("constraint %q column %d: unique constraints cannot have sort or opclass", c.name, j+1)
+			}
+			if col.FieldByName("Collation").String() != "" {
+				return nil, fmt.Errorf("constraint %q column %d: unique constraints cannot have a collation", c.name, j+1)
+			}
+			if exprF := col.FieldByName("Expr"); exprF.IsValid() && exprF.String() != "" {
+				return nil, fmt.Errorf("constraint %q column %d: unique constraints cannot have expression columns", c.name, j+1)
+			}
+			fname, err := columnField(col)
+			if err != nil {
+				return nil, fmt.Errorf("constraint %q column %d: %w", c.name, j+1, err)
+			}
+			f := stmt.Schema.LookUpField(fname)
+			if f == nil || f.DBName == "" {
+				return nil, fmt.Errorf("constraint %q column %d: field %q is not mapped to a column", c.name, j+1, fname)
+			}
+			if err := unmigrated(stmt, f); err != nil {
+				return nil, fmt.Errorf("constraint %q column %d: %w", c.name, j+1, err)
+			}
+			c.columns = append(c.columns, f.DBName)
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
+
+// clusterIndex returns the table of the model and the name of its clustering index (see
+// IndexDefinition.Cluster), if any. The definitions are validated when the model is migrated.
+func clusterIndex(db *gorm.DB, model any) (string, string, error) {
+	if model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+		return "", "", nil
+	}
+	defs, ok := indexDefinitions(receiver(model))
+	if !ok {
+		return "", "", nil
+	}
+	for _, def := range defs {
+		def = reflect.Indirect(def)
+		if clusterF := def.FieldByName("Cluster"); clusterF.IsValid() && clusterF.Bool() {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return "", "", err
+			}
+			return stmt.Schema.Table, contentName(db, stmt.Schema, def), nil
+		}
+	}
+	return "", "", nil
+}
+
+// flaggedIndexes returns the names of the indexes of the model whose definitions set the given
+// bool field, e.g. "Concurrent" for the indexes that are built concurrently.
+func flaggedIndexes(db *gorm.DB, model any, flag string) []string {
+	if model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+		return nil
+	}
+	defs, ok := indexDefinitions(receiver(model))
+	if !ok {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil
+	}
+	var names []string
+	for _, def := range defs {
+		def = reflect.Indirect(def)
+		if f := def.FieldByName(flag); f.IsValid() && f.Bool() {
+			if name := contentName(db, stmt.Schema, def); !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// contentName returns the name of the given index definition of the schema, suffixed with a short
+// hash of its content if the loader hashes the names of indexes (see WithContentHashedNames).
+// The hash covers the column names rather than the Go field names, so renaming a field that keeps
+// its column keeps the name, and the name is truncated before the suffix to fit maxIdentLen.
+func contentName(db *gorm.DB, sch *schema.Schema, def reflect.Value) string {
+	name := tableName(def.FieldByName("Name").String(), sch.Table)
+	if d, ok := tablesOf(db); !ok || !d.hashNames {
+		return name
+	}
+	h := sha256.New()
+	// dbName returns the column name of the given field name, if it resolves.
+	dbName := func(fname string) string {
+		if f := sch.LookUpField(fname); f != nil && f.DBName != "" {
+			return f.DBName
+		}
+		return fname
+	}
+	// selected returns the column name of the given selector, if it resolves.
+	selected := func(sel reflect.Value) string {
+		if !sel.IsValid() || sel.IsNil() {
+			return ""
+		}
+		fname, _ := fieldNameFromSelectorValue(sel)
+		return dbName(fname)
+	}
+	// column returns the column name of the given column, if it resolves.
+	column := func(col reflect.Value) string {
+		fname, _ := columnField(col)
+		return dbName(fname)
+	}
+	for _, set := range []string{"Columns", "Include"} {
+		cols := def.FieldByName(set)
+		for j := 0; cols.IsValid() && j < cols.Len(); j++ {
+			col := reflect.Indirect(cols.Index(j))
+			fmt.Fprintf(h, "%s(%s", set, column(col))
+			for _, f := range []string{"Sort", "Nulls", "OpClass", "Collation", "Expr"} {
+				fmt.Fprintf(h, "|%s", col.FieldByName(f).String())
+			}
+			fmt.Fprintf(h, "|%d", col.FieldByName("Length").Int())
+			if params, _ := opClassParams(col); params != "" {
+				// Appended only if set, to keep the names of existing definitions.
+				fmt.Fprintf(h, "|%s", params)
+			}
+			refs := col.FieldByName("Refs")
+			for k := 0; refs.IsValid() && k < refs.Len(); k++ {
+				fmt.Fprintf(h, "|%s", selected(refs.Index(k)))
+			}
+			h.Write([]byte(")"))
+		}
+	}
+	fmt.Fprintf(h, "unique=%t;type=%s;where=%s", def.FieldByName("Unique").Bool(),
+		strings.ToLower(strings.TrimSpace(def.FieldByName("Type").String())), strings.TrimSpace(def.FieldByName("Where").String()))
+	if f := def.FieldByName("NullsNotDistinct"); f.IsValid() && f.Bool() {
+		// Appended only if set, to keep the names of existing definitions.
+		h.Write([]byte(";nulls_not_distinct"))
+	}
+	conds := def.FieldByName("Conds")
+	for k := 0; conds.IsValid() && k < conds.Len(); k++ {
+		c := conds.Index(k)
+		fmt.Fprintf(h, ";%s=%#v", selected(c.FieldByName("Sel")), c.FieldByName("Value").Interface())
+	}
+	suffix := fmt.Sprintf("_%x", h.Sum(nil)[:4])
+	if len(name)+len(suffix) > maxIdentLen {
+		name = name[:maxIdentLen-len(suffix)]
+	}
+	return name + suffix
+}
+
+// maxIdentLen is the maximum length in bytes of the hashed index names, the identifier limit of
+// PostgreSQL, the strictest of the supported dialects.
+const maxIdentLen = 63
+
+// tableName replaces the TablePlaceholder of the given index name with the name of its table.
+func tableName(name, table string) string {
+	if _, t, ok := strings.Cut(table, "."); ok {
+		table = t
+	}
+	return strings.ReplaceAll(name, TablePlaceholder, table)
+}
+
+// rowLevelSecurity returns the statements enabling the row-level security of the model,
+// and creating its policies (see RLSSpec).
+func rowLevelSecurity(db *gorm.DB, model any) ([]string, error) {
+	if model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+		return nil, nil
+	}
+	r, ok := receiver(model).Interface().(interface{ RLS() *RLSSpec })
+	if !ok {
+		return nil, nil
+	}
+	spec := r.RLS()
+	if spec == nil {
+		return nil, nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	if name := db.Dialector.Name(); name != "postgres" {
+		return nil, fmt.Errorf("row-level security of %s: not supported by %s", stmt.Schema.Name, name)
+	}
+	table := stmt.Quote(stmt.Schema.Table)
+	stmts := []string{fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY", table)}
+	if spec.Force {
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s FORCE ROW LEVEL SECURITY", table))
+	}
+	seen := make(map[string]bool, len(spec.Policies))
+	for _, p := range spec.Policies {
+		if p.Name == "" {
+			return nil, fmt.Errorf("row-level security of %s: policy name is required", stmt.Schema.Name)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("policy %q: declared more than once", p.Name)
+		}
+		seen[p.Name] = true
+		using, check := strings.TrimSpace(p.Using), strings.TrimSpace(p.Check)
+		if strings.Contains(using, ";") || strings.Contains(check, ";") {
+			return nil, fmt.Errorf("policy %q: expressions must not contain ';'", p.Name)
+		}
+		cmd := strings.ToLower(p.Command)
+		switch {
+		case cmd != "" && !slices.Contains([]string{"all", "select", "insert", "update", "delete"}, cmd):
+			return nil, fmt.Errorf("policy %q: unsupported command %q", p.Name, p.Command)
+		case using == "" && check == "":
+			return nil, fmt.Errorf("policy %q: requires a using or check expression", p.Name)
+		case cmd == "insert" && using != "":
+			return nil, fmt.Errorf("policy %q: insert policies cannot have a using expression", p.Name)
+		case (cmd == "select" || cmd == "delete") && check != "":
+			return nil, fmt.Errorf("policy %q: %s policies cannot have a check expression", p.Name, cmd)
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "CREATE POLICY %s ON %s", stmt.Quote(p.Name), table)
+		if cmd != "" {
+			b.WriteString(" FOR " + strings.ToUpper(cmd))
+		}
+		if len(p.Roles) > 0 {
+			roles := make([]string, len(p.Roles))
+			for i, role := range p.Roles {
+				switch strings.ToUpper(role) {
+				case "PUBLIC", "CURRENT_ROLE", "CURRENT_USER", "SESSION_USER":
+					roles[i] = strings.ToUpper(role)
+				default:
+					roles[i] = stmt.Quote(role)
+				}
+			}
+			b.WriteString(" TO " + strings.Join(roles, ", "))
+		}
+		if using != "" {
+			b.WriteString(" USING (" + using + ")")
+		}
+		if check != "" {
+			b.WriteString(" WITH CHECK (" + check + ")")
+		}
+		stmts = append(stmts, b.String())
+	}
+	return stmts, nil
+}
+
+// autoIncrement is the start value of the auto-increment column of a table.
+type autoIncrement struct {
+	table, column string
+	start         int64
+}
+
+// autoIncrementStart returns the start value declared by the AutoIncrementStart() int64
+// method of the model, if any.
+func autoIncrementStart(db *gorm.DB, model any) (*autoIncrement, error) {
+	if model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+		return nil, nil
+	}
+	r, ok := receiver(model).Interface().(interface{ AutoIncrementStart() int64 })
+	if !ok {
+		return nil, nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	start := r.AutoIncrementStart()
+	switch name := db.Dialector.Name(); {
+	case name == "sqlite":
+		return nil, fmt.Errorf("auto-increment start of %s: not supported by sqlite", stmt.Schema.Name)
+	case start < 1:
+		return nil, fmt.Errorf("auto-increment start of %s: must be positive, got %d", stmt.Schema.Name, start)
+	}
+	for _, f := range stmt.Schema.Fields {
+		if f.AutoIncrement && f.DBName != "" {
+			return &autoIncrement{table: stmt.Schema.Table, column: f.DBName, start: start}, nil
+		}
+	}
+	return nil, fmt.Errorf("auto-increment start of %s: no auto-increment column", stmt.Schema.Name)
+}
+
+// def returns the definition of the constraint, quoted using the given session.
+func (c uniqueConstraint) def(db *gorm.DB) string {
+	cols := make([]string, len(c.columns))
+	for i, col := range c.columns {
+		cols[i] = db.Statement.Quote(col)
+	}
+	unique := "UNIQUE"
+	if c.nullsNotDistinct {
+		unique += " NULLS NOT DISTINCT"
+	}
+	return fmt.Sprintf("CONSTRAINT %s %s (%s)", db.Statement.Quote(c.name), unique, strings.Join(cols, ","))
+}
+
+// constraintComment is the comment of a table constraint.
+type constraintComment struct {
+	table   string
+	name    string
+	comment string
+}
+
+// constraintComments returns the comments of the constraints of the model, declared by the Comment
+// of its unique constraints, and by its ConstraintComments() method that maps the name of a check,
+// foreign-key or unique constraint to its comment. Comments of foreign keys that are not created
+// (fks is false) are skipped.
+func constraintComments(db *gorm.DB, model any, cs []uniqueConstraint, checks []tableCheck, fks bool) ([]constraintComment, error) {
+	var comments []constraintComment
+	for _, c := range cs {
+		if c.comment != "" {
+			comments = append(comments, constraintComment{table: c.table, name: c.name, comment: c.comment})
+		}
+	}
+	commenter, ok := receiver(model).Interface().(interface {
+		ConstraintComments() map[string]string
+	})
+	if !ok {
+		return comments, nil
+	}
+	// Checks might be declared by ColumnChecks(), hence the migrated value is parsed.
+	tx, value, err := migrationTarget(db, model)
+	if err != nil {
+		return nil, err
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.ParseWithSpecialTableName(value, tx.Statement.Table); err != nil {
+		return nil, err
+	}
+	sch := stmt.Schema
+	tables := make(map[string]string)
+	for name := range sch.ParseCheckConstraints() {
+		tables[name] = sch.Table
+	}
+	for _, rel := range sch.Relationships.Relations {
+		if c := rel.ParseConstraint(); c != nil {
+			tables[c.Name] = ""
+			if fks {
+				tables[c.Name] = c.Schema.Table
+			}
+		}
+	}
+	for _, c := range checks {
+		tables[c.name] = sch.Table
+	}
+	for _, c := range cs {
+		tables[c.name] = c.table
+	}
+	byName := commenter.ConstraintComments()
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		table, ok := tables[name]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("comment on %q: no such constraint on %s", name, indirectType(reflect.TypeOf(model)).Name())
+		case table != "":
+			comments = append(comments, constraintComment{table: table, name: name, comment: byName[name]})
+		}
+	}
+	return comments, nil
+}
+
+// indexDefinitions calls the Indexes() method of the given receiver (if any), and returns
+// its definitions followed by the ones of the embedded base models (see flattenFields).
+// Hence, a model that declares its own Indexes() method, which shadows the method of its
+// bases in Go, gets the indexes of its bases as well.
+func indexDefinitions(recv reflect.Value) ([]reflect.Value, bool) {
+	var (
+		defs   []reflect.Value
+		method = recv.MethodByName("Indexes")
+		// Unexpected signatures are ignored gracefully.
+		own = method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() == 1
+	)
+	if s := reflect.Indirect(recv); s.Kind() == reflect.Struct {
+		for i := 0; i < s.NumField(); i++ {
+			if !flattened(s.Type().Field(i)) {
+				continue
+			}
+			base := s.Field(i)
+			switch {
+			case base.Kind() != reflect.Pointer:
+				base = base.Addr()
+			case base.IsNil():
+				base = reflect.New(base.Type().Elem())
+			}
+			// The method of the receiver might be the one promoted from this base.
+			if m := base.MethodByName("Indexes"); own && m.IsValid() && m.Type() == method.Type() {
+				own = false
+			}
+			bdefs, _ := indexDefinitions(base)
+			defs = append(defs, bdefs...)
+		}
+	}
+	if own {
+		// Call Indexes() reflectively; result is a slice of IndexDefinition[T] (unknown T).
+		if out := method.Call(nil)[0]; out.Kind() == reflect.Slice {
+			ownDefs := make([]reflect.Value, out.Len())
+			for i := range ownDefs {
+				ownDefs[i] = out.Index(i)
+			}
+			defs = append(ownDefs, defs...)
+		}
+	}
+	return defs, len(defs) > 0
+}
+
+// checkDefinitions returns the result of the Checks() method of the given receiver, if any.
+func checkDefinitions(recv reflect.Value) (reflect.Value, bool) {
+	method := recv.MethodByName("Checks")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return reflect.Value{}, false
+	}
+	out := method.Call(nil)[0]
+	if out.Kind() != reflect.Slice || out.Len() == 0 {
+		return reflect.Value{}, false
+	}
+	return out, true
+}
+
+// -------- internals --------
+
+func collectIndexTagsFromIndexesValue(stmt *gorm.Statement, baseStruct reflect.Type, defs []reflect.Value) (map[string][]string, error) {
+	fieldToIndexTags := map[string][]string{}
+	declared := map[string]bool{}
+	var (
+		selErrs []error
+		cluster string
+	)
+
+	for i, def := range defs {
+		if def.Kind() == reflect.Pointer {
+			def = def.Elem()
+		}
+		if def.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("Indexes()[%d] is not a struct", i)
+		}
+		// GORM merges the columns of indexes of the same name. Unnamed indexes are named by GORM.
+		if resolved := tableName(def.FieldByName("Name").String(), stmt.Schema.Table); resolved != "" {
+			if declared[resolved] {
+				return nil, fmt.Errorf("index %q declared twice", resolved)
+			}
+			declared[resolved] = true
+		}
+		// Columns built programmatically might be empty, which GORM silently ignores.
+		if colsF := def.FieldByName("Columns"); colsF.IsValid() && colsF.Len() == 0 {
+			return nil, fmt.Errorf("index %q has no columns", def.FieldByName("Name").String())
+		}
+
+		if clusterF := def.FieldByName("Cluster"); clusterF.IsValid() && clusterF.Bool() {
+			name := def.FieldByName("Name").String()
+			switch {
+			case stmt.DB.Dialector.Name() != "postgres":
+				return nil, fmt.Errorf("index %q: clustering indexes are supported only by PostgreSQL", name)
+			case cluster != "" && cluster != name:
+				return nil, fmt.Errorf("index %q: %s is already clustered on index %q", name, stmt.Schema.Table, cluster)
+			}
+			cluster = name
+		}
+
+		if f := def.FieldByName("Concurrent"); f.IsValid() && f.Bool() && def.FieldByName("Style").String() != "" {
+			return nil, fmt.Errorf("index %q: constraints cannot be built concurrently", def.FieldByName("Name").String())
+		}
+		if f := def.FieldByName("Replace"); f.IsValid() && f.Bool() {
+			switch name := def.FieldByName("Name").String(); {
+			case def.FieldByName("Style").String() != "":
+				return nil, fmt.Errorf("index %q: constraints cannot be replaced", name)
+			case stmt.DB.Dialector.Name() == "mysql":
+				return nil, fmt.Errorf("index %q: replacing indexes is not supported by mysql", name)
+			}
+		}
+		// Unique constraints are created by the loader (see uniqueConstraints).
+		if styleF := def.FieldByName("Style"); styleF.IsValid() && styleF.String() != "" {
+			continue
+		}
+
+		if commentF := def.FieldByName("Comment"); commentF.IsValid() && commentF.String() != "" {
+			return nil, fmt.Errorf("index %q: Comment requires a constraint Style", def.FieldByName("Name").String())
+		}
+
+		// Expect fields: Name string, Columns []Col[?], Unique bool, Where string
+		nameF := def.FieldByName("Name")
+		colsF := def.FieldByName("Columns")
+		uniqueF := def.FieldByName("Unique")
+		whereF := def.FieldByName("Where")
+
+		if !nameF.IsValid() || !colsF.IsValid() || !uniqueF.IsValid() || !whereF.IsValid() {
+			return nil, fmt.Errorf("Indexes()[%d] doesn't look like IndexDefinition", i)
+		}
+		name := nameF.String()
+		unique := uniqueF.Bool()
+		var typ string
+		if typeF := def.FieldByName("Type"); typeF.IsValid() {
+			typ = strings.TrimSpace(typeF.String())
+		}
+		typ, err := indexType(name, typ, def.FieldByName("Columns"), knownOpClasses(stmt.DB))
+		if err != nil {
+			return nil, err
+		}
+		var class string
+		if strings.EqualFold(typ, "fulltext") {
+			if colsF, err = fulltextColumns(stmt, name, unique, colsF); err != nil {
+				return nil, err
+			}
+			// MySQL creates FULLTEXT indexes, and PostgreSQL indexes the text search vector using gin.
+			if stmt.DB.Dialector.Name() == "mysql" {
+				class, typ = "FULLTEXT", ""
+			} else {
+				typ = "gin"
+			}
+		}
+		if err := checkAccessMethod(stmt, name, typ, def); err != nil {
+			return nil, err
+		}
+		var option string
+		if invisibleF := def.FieldByName("Invisible"); invisibleF.IsValid() && invisibleF.Bool() {
+			switch {
+			case stmt.DB.Dialector.Name() != "mysql":
+				return nil, fmt.Errorf("index %q: invisible indexes are supported only by MySQL and MariaDB", name)
+			case isMariaDB(stmt.DB):
+				option = "IGNORED"
+			default:
+				option = "INVISIBLE"
+			}
+		}
+		include, err := includeColumns(stmt, name, def.FieldByName("Include"))
+		if err != nil {
+			return nil, err
+		}
+		nnd, err := nullsNotDistinct(stmt, name, def)
+		if err != nil {
+			return nil, err
+		}
+		where := strings.TrimSpace(whereF.String())
+		// Semicolons are checked before the literals of Conds are appended, which might contain them.
+		if strings.Contains(where, ";") {
+			return nil, fmt.Errorf("index %q: where must not contain ';'", name)
+		}
+		if where == SoftDelete {
+			col, ok := softDeleteColumn(stmt.Schema)
+			if !ok {
+				return nil, fmt.Errorf("index %q: %s requires a gorm.DeletedAt field on %s", name, SoftDelete, stmt.Schema.Name)
+			}
+			where = quoteReserved(stmt, col) + " IS NULL"
+		}
+		if ref, ok := foreignReference(stmt.Schema.Table, where); ok {
+			return nil, fmt.Errorf("index %q: where references column %q of another table", name, ref)
+		}
+		if err := checkPredicateColumns(stmt, name, where); err != nil {
+			return nil, err
+		}
+		if fn, ok := volatileFunc(where); ok && unique && stmt.DB.Dialector.Name() == "postgres" {
+			return nil, fmt.Errorf("index %q: where of a unique index must be immutable, but calls %s", name, fn)
+		}
+		where = boolPredicate(stmt, where)
+		if condsF := def.FieldByName("Conds"); condsF.IsValid() && condsF.Kind() == reflect.Slice {
+			preds := make([]string, 0, condsF.Len()+1)
+			if where != "" {
+				preds = append(preds, where)
+			}
+			for j := 0; j < condsF.Len(); j++ {
+				p, err := condPredicate(stmt, condsF.Index(j))
+				if err != nil {
+					return nil, fmt.Errorf("index %q condition %d: %w", name, j+1, err)
+				}
+				preds = append(preds, p)
+			}
+			where = strings.Join(preds, " AND ")
+		}
+		if name == cluster && where != "" {
+			return nil, fmt.Errorf("index %q: partial indexes cannot be clustering indexes", name)
+		}
+
+		if err := checkPredicate(stmt, name, where); err != nil {
+			return nil, err
+		}
+		if len(include) > 0 {
+			cols := make([]string, len(include))
+			for j, f := range include {
+				cols[j] = stmt.Quote(f.DBName)
+			}
+			clause := "INCLUDE (" + strings.Join(cols, ",") + ")"
+			if stmt.DB.Dialector.Name() == "sqlserver" && where != "" {
+				// SQL Server requires INCLUDE to precede WHERE, but GORM appends the option after it.
+				clause += " WHERE " + where
+				where = ""
+			}
+			option = strings.TrimSpace(clause + " " + option)
+		}
+		if nnd {
+			// The clause follows the included columns, and precedes the storage parameters.
+			option = strings.TrimSpace(option + " NULLS NOT DISTINCT")
+		}
+		params, err := storageParams(stmt.DB.Dialector.Name(), name, typ, def)
+		if err != nil {
+			return nil, err
+		}
+		if params != "" {
+			option = strings.TrimSpace(option + " WITH (" + params + ")")
+		}
+		if tsF := def.FieldByName("Tablespace"); tsF.IsValid() && strings.TrimSpace(tsF.String()) != "" {
+			if stmt.DB.Dialector.Name() != "postgres" {
+				return nil, fmt.Errorf("index %q: tablespaces are supported only by PostgreSQL", name)
+			}
+			option = strings.TrimSpace(option + " TABLESPACE " + stmt.Quote(strings.TrimSpace(tsF.String())))
+		}
+		// Commas separate the settings of the index tag, and semicolons the settings of the field.
+		where = strings.ReplaceAll(where, ",", `\,`)
+		where = strings.ReplaceAll(where, ";", tagSemicolon)
+		option = strings.ReplaceAll(option, ",", `\,`)
+
+		if colsF.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("Index %q: Columns is not a slice", name)
+		}
+		n, dialect := colsF.Len(), stmt.DB.Dialector.Name()
+		if dialect == "postgres" {
+			// Included columns count towards the limit of PostgreSQL, but not of SQL Server.
+			n += len(include)
+		}
+		if limit := indexColumnLimits[dialect]; limit > 0 && n > limit {
+			return nil, fmt.Errorf("index %q: %d columns exceed the limit of %d columns per index of %s", name, n, limit, dialect)
+		}
+		for j := 0; j < colsF.Len(); j++ {
+			col := colsF.Index(j)
+			if col.Kind() == reflect.Pointer {
+				col = col.Elem()
+			}
+			if col.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("Index %q column %d: not a struct", name, j+1)
+			}
+
+			sortF := col.FieldByName("Sort") // string
+			nullF := col.FieldByName("Nulls")
+			switch strings.ToLower(strings.TrimSpace(sortF.String())) {
+			case "", "asc", "desc":
+			default:
+				return nil, fmt.Errorf("index %q column %d: invalid sort %q, expected \"asc\" or \"desc\"", name, j+1, sortF.String())
+			}
+			switch strings.ToLower(strings.TrimSpace(nullF.String())) {
+			case "", "first", "last":
+			default:
+				return nil, fmt.Errorf("index %q column %d: invalid nulls %q, expected \"first\" or \"last\"", name, j+1, nullF.String())
+			}
+			var opclass string
+			if opF := col.FieldByName("OpClass"); opF.IsValid() {
+				opclass = strings.TrimSpace(opF.String())
+			}
+			if opclass != "" && !opClassName.MatchString(opclass) {
+				return nil, fmt.Errorf("index %q column %d: invalid operator class %q", name, j+1, opclass)
+			}
+			if err := checkOpClass(stmt, name, j, opclass); err != nil {
+				return nil, err
+			}
+			if params, err := opClassParams(col); err != nil {
+				return nil, fmt.Errorf("index %q column %d: %w", name, j+1, err)
+			} else if params != "" {
+				opclass += " (" + params + ")"
+			}
+
+			var collation string
+			if cF := col.FieldByName("Collation"); cF.IsValid() && strings.TrimSpace(cF.String()) != "" {
+				if collation, err = collateClause(stmt, strings.TrimSpace(cF.String())); err != nil {
+					return nil, fmt.Errorf("index %q column %d: %w", name, j+1, err)
+				}
+			}
+
+			var length int64
+			if lF := col.FieldByName("Length"); lF.IsValid() && lF.Int() != 0 {
+				switch d, _ := tablesOf(stmt.DB); {
+				case lF.Int() < 0:
+					return nil, fmt.Errorf("index %q column %d: negative prefix length %d", name, j+1, lF.Int())
+				case stmt.DB.Dialector.Name() == "mysql":
+					length = lF.Int()
+				case d.strictLengths:
+					return nil, fmt.Errorf("index %q column %d: prefix lengths are supported only by MySQL", name, j+1)
+				}
+			}
+
+			if errF := col.FieldByName("err"); errF.IsValid() && errF.String() != "" {
+				return nil, fmt.Errorf("index %q column %d: %s", name, j+1, errF.String())
+			}
+			var fname, expr string
+			if exprF := col.FieldByName("Expr"); exprF.IsValid() && strings.TrimSpace(exprF.String()) != "" {
+				if fname, expr, err = exprColumn(stmt, col); err != nil {
+					return nil, fmt.Errorf("index %q column %d: %w", name, j+1, err)
+				}
+				if fname == "" {
+					// Expressions without column references are set on the first column of the table.
+					if fname = anchorField(stmt.Schema); fname == "" {
+						return nil, fmt.Errorf("index %q column %d: %s has no column to set the expression on", name, j+1, stmt.Schema.Name)
+					}
+				}
+			} else {
+				// Unresolved selectors are reported together, to fix them in one pass.
+				if fname, err = columnField(col); err != nil {
+					selErrs = append(selErrs, fmt.Errorf("index %q column %d: %w", name, j+1, err))
+					continue
+				}
+				f := stmt.Schema.LookUpField(fname)
+				if f == nil || f.DBName == "" {
+					selErrs = append(selErrs, fmt.Errorf("index %q column %d: field %q is not mapped to a column", name, j+1, fname))
+					continue
+				}
+				if err := unmigrated(stmt, f); err != nil {
+					return nil, fmt.Errorf("index %q column %d: %w", name, j+1, err)
+				}
+				for _, inc := range include {
+					if inc == f {
+						return nil, fmt.Errorf("index %q: column %q is both a key and an included column", name, f.DBName)
+					}
+				}
+				if strings.EqualFold(typ, "gin") && opclass == "" && stmt.DB.Dialector.Name() == "postgres" {
+					if dt := dataTypeOf(stmt.DB, f); !ginIndexable(dt) {
+						return nil, fmt.Errorf("index %q: column %q of type %s has no default operator class for gin, "+
+							"use an array, jsonb or tsvector column, or an operator class such as gin_trgm_ops", name, f.DBName, dt)
+					}
+				}
+				if opclass != "" || collation != "" {
+					expr = stmt.Quote(f.DBName)
+				}
+			}
+
+			parts := []string{
+				"index:" + contentName(stmt.DB, stmt.Schema, def),
+				fmt.Sprintf("priority:%d", j+1),
+			}
+			dir := sortF.String()
+			if d, ok := tablesOf(stmt.DB); ok && d.explicitSort && strings.TrimSpace(dir) == "" && class == "" && (typ == "" || strings.EqualFold(typ, "btree")) {
+				dir = "asc"
+			}
+			order := sortOrder(dir, nullF.String())
+			switch {
+			case expr != "":
+				// The collation and operator class must precede the ordering, which GORM
+				// appends to the expression. Hence, all are part of the expression.
+				if collation != "" {
+					expr += " " + collation
+					if stmt.DB.Dialector.Name() == "mysql" {
+						// MySQL supports collations only on functional key parts.
+						expr = "(" + expr + ")"
+					}
+				}
+				if opclass != "" {
+					expr += " " + opclass
+				}
+				if order != "" {
+					expr += " " + order
+				}
+				// Commas separate the settings of the index tag.
+				parts = append(parts, "expression:"+strings.ReplaceAll(expr, ",", `\,`))
+			case order != "":
+				parts = append(parts, "sort:"+order)
+			}
+			if length > 0 {
+				if expr != "" {
+					return nil, fmt.Errorf("index %q column %d: prefix lengths are not supported by expressions, operator classes or collations", name, j+1)
+				}
+				parts = append(parts, fmt.Sprintf("length:%d", length))
+			}
+			if j == 0 && unique {
+				parts = append(parts, "unique")
+			}
+			if j == 0 && class != "" {
+				parts = append(parts, "class:"+class)
+			}
+			if j == 0 && typ != "" {
+				parts = append(parts, "type:"+typ)
+			}
+			if j == 0 && option != "" {
+				parts = append(parts, "option:"+option)
+			}
+			if j == 0 && where != "" {
+				parts = append(parts, "where:"+where)
+			}
+
+			fieldToIndexTags[fname] = append(fieldToIndexTags[fname], strings.Join(parts, ","))
+		}
+	}
+	if len(selErrs) > 0 {
+		return nil, errors.Join(selErrs...)
+	}
+	return fieldToIndexTags, nil
+}
+
+// tagSemicolon replaces the semicolons of the predicates in the gorm tags of the indexes, as
+// GORM splits the tags on them without unescaping. They are restored by restoreSemicolons.
+const tagSemicolon = "\x1f"
+
+// restoreSemicolons restores the semicolons of the predicates of the given statements.
+func restoreSemicolons(stmts []string) {
+	for i, stmt := range stmts {
+		stmts[i] = strings.ReplaceAll(stmt, tagSemicolon, ";")
+	}
+}
+
+// methodParams are the storage parameters of the builtin index access methods of PostgreSQL.
+var methodParams = map[string][]string{
+	"btree":  {"fillfactor", "deduplicate_items"},
+	"hash":   {"fillfactor"},
+	"gist":   {"fillfactor", "buffering"},
+	"spgist": {"fillfactor"},
+	"gin":    {"fastupdate", "gin_pending_list_limit"},
+	"brin":   {"pages_per_range", "autosummarize"},
+}
+
+// sqlserverIndexOptions are the options of SQL Server indexes, set using their WITH clause.
+var sqlserverIndexOptions = []string{"fillfactor", "pad_index", "sort_in_tempdb", "ignore_dup_key", "statistics_norecompute",
+	"statistics_incremental", "drop_existing", "online", "resumable", "max_duration", "allow_row_locks", "allow_page_locks",
+	"optimize_for_sequential_key", "maxdop", "data_compression", "xml_compression"}
+
+// checkStorageParam validates that the given storage parameter is supported by the access method
+// of a PostgreSQL index, e.g. pages_per_range by brin, or by SQL Server indexes. The parameters of
+// the access methods of extensions are not validated.
+func checkStorageParam(dialect, name, typ, param string) error {
+	switch method := cmp.Or(strings.ToLower(typ), "btree"); {
+	case dialect == "sqlserver" && !slices.Contains(sqlserverIndexOptions, param):
+		return fmt.Errorf("index %q: storage parameter %q is not supported by sqlserver", name, param)
+	case dialect != "postgres" || methodParams[method] == nil || slices.Contains(methodParams[method], param):
+		return nil
+	default:
+		return fmt.Errorf("index %q: storage parameter %q is not supported by %s indexes, expected one of %s",
+			name, param, method, strings.Join(methodParams[method], ", "))
+	}
+}
+
+// storageParams returns the storage parameters of the given index definition, rendered as the
+// content of its WITH clause, or an empty string if it has none or the dialect does not support them.
+func storageParams(dialect, name, typ string, def reflect.Value) (string, error) {
+	params := make(map[string]string)
+	if withF := def.FieldByName("With"); withF.IsValid() {
+		for it := withF.MapRange(); it.Next(); {
+			k, v := strings.ToLower(strings.TrimSpace(it.Key().String())), strings.TrimSpace(it.Value().String())
+			if !storageParam.MatchString(k) || !storageParam.MatchString(v) {
+				return "", fmt.Errorf("index %q: invalid storage parameter %s=%s", name, k, v)
+			}
+			if err := checkStorageParam(dialect, name, typ, k); err != nil {
+				return "", err
+			}
+			params[k] = v
+		}
+	}
+	if hintF := def.FieldByName("Workload"); hintF.IsValid() && hintF.String() != "" {
+		ff, ok := workloadFillFactors[WorkloadHint(hintF.String())]
+		if !ok {
+			return "", fmt.Errorf("index %q: unknown workload hint %q", name, hintF.String())
+		}
+		// Access methods such as gin and brin have no fillfactor.
+		switch t := strings.ToLower(typ); {
+		case params["fillfactor"] != "":
+		case t == "" || t == "btree" || t == "hash" || t == "gist" || t == "spgist" || dialect == "sqlserver":
+			params["fillfactor"] = ff
+		}
+	}
+	if len(params) == 0 || (dialect != "postgres" && dialect != "sqlserver") {
+		return "", nil
+	}
+	kvs := make([]string, 0, len(params))
+	for _, k := range slices.Sorted(maps.Keys(params)) {
+		kvs = append(kvs, k+"="+params[k])
+	}
+	return strings.Join(kvs, ","), nil
+}
+
+// exprColumn returns the expression of the given column, with the placeholders of its references
+// replaced by their quoted column names, and the name of the field of its first reference, if any.
+func exprColumn(stmt *gorm.Statement, col reflect.Value) (string, string, error) {
+	expr := strings.TrimSpace(col.FieldByName("Expr").String())
+	if strings.Contains(expr, ";") {
+		return "", "", fmt.Errorf("expression must not contain ';'")
+	}
+	if vec := col.FieldByName("tsvector"); vec.IsValid() && vec.Bool() && stmt.DB.Dialector.Name() != "postgres" {
+		return "", "", fmt.Errorf("text search vectors are supported only by PostgreSQL")
+	}
+	var (
+		first string
+		names []string
+	)
+	refsF := col.FieldByName("Refs")
+	for i := 0; refsF.IsValid() && i < refsF.Len(); i++ {
+		fname, err := fieldNameFromSelectorValue(refsF.Index(i))
+		if err != nil {
+			return "", "", fmt.Errorf("reference %d: %w", i+1, err)
+		}
+		f := stmt.Schema.LookUpField(fname)
+		if f == nil || f.DBName == "" {
+			return "", "", fmt.Errorf("reference %d: field %q is not mapped to a column", i+1, fname)
+		}
+		if err := unmigrated(stmt, f); err != nil {
+			return "", "", fmt.Errorf("reference %d: %w", i+1, err)
+		}
+		if refsJSON := col.FieldByName("jsonRefs"); refsJSON.IsValid() && refsJSON.Bool() {
+			if stmt.DB.Dialector.Name() != "postgres" {
+				return "", "", fmt.Errorf("json paths are supported only by PostgreSQL")
+			}
+			if dt := strings.ToLower(dataTypeOf(stmt.DB, f)); dt != "json" && dt != "jsonb" {
+				return "", "", fmt.Errorf("column %q of type %s is not a json or jsonb column", f.DBName, dt)
+			}
+		}
+		if first == "" {
+			first = fname
+		}
+		names = append(names, stmt.Quote(f.DBName))
+	}
+	var err error
+	expr = exprPlaceholder.ReplaceAllStringFunc(expr, func(p string) string {
+		n, _ := strconv.Atoi(p[1 : len(p)-1])
+		if n < 1 || n > len(names) {
+			err = fmt.Errorf("placeholder %s has no reference", p)
+			return p
+		}
+		return names[n-1]
+	})
+	if err != nil {
+		return "", "", err
+	}
+	// Expressions other than function calls must be parenthesized, and MySQL
+	// requires all expressions (functional key parts) to be parenthesized.
+	if !funcCall(expr) || stmt.DB.Dialector.Name() == "mysql" {
+		expr = "(" + expr + ")"
+	}
+	return first, expr, nil
+}
+
+// exprPlaceholder matches the placeholders of column references in expressions, e.g. {1}.
+var exprPlaceholder = regexp.MustCompile(`\{\d+\}`)
+
+var funcName = regexp.MustCompile(`^[A-Za-z_][\w.]*\(`)
+
+// funcCall reports whether the given expression is a single function call, e.g. "lower(name)".
+func funcCall(expr string) bool {
+	if !funcName.MatchString(expr) {
+		return false
+	}
+	depth, quoted := 0, false
+	for i, r := range expr {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth--; depth == 0 {
+				return i == len(expr)-1
+			}
+		}
+	}
+	return false
+}
+
+// anchorField returns the name of the first top-level field of the schema that is mapped to a column.
+func anchorField(sch *schema.Schema) string {
+	for _, f := range sch.Fields {
+		if f.DBName != "" && len(f.StructField.Index) == 1 && !f.StructField.Anonymous {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+// indexColumnLimits are the maximum number of columns in an index, per dialect.
+var indexColumnLimits = map[string]int{
+	"postgres":  32,
+	"mysql":     16,
+	"sqlserver": 32,
+}
+
+// includeColumns returns the fields of the included (non-key) columns of an index. Included
+// columns are not ordered, hence they cannot have sort, nulls ordering or operator class.
+func includeColumns(stmt *gorm.Statement, name string, cols reflect.Value) ([]*schema.Field, error) {
+	if !cols.IsValid() || cols.Len() == 0 {
+		return nil, nil
+	}
+	if d := stmt.DB.Dialector.Name(); d != "postgres" && d != "sqlserver" {
+		if t, ok := tablesOf(stmt.DB); ok && t.dropIncludes {
+			stmt.DB.Logger.Warn(context.Background(), "index %q: included columns are not supported by %s and were dropped", name, d)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("index %q: included columns are supported only by PostgreSQL and SQL Server", name)
+	}
+	fields := make([]*schema.Field, 0, cols.Len())
+	for j := 0; j < cols.Len(); j++ {
+		col := reflect.Indirect(cols.Index(j))
+		if col.FieldByName("Sort").String() != "" || col.FieldByName("Nulls").String() != "" || col.FieldByName("OpClass").String() != "" || col.FieldByName("OpClassParams").Len() > 0 {
+			return nil, fmt.Errorf("index %q included column %d: included columns cannot have sort, nulls or opclass", name, j+1)
+		}
+		if col.FieldByName("Collation").String() != "" {
+			return nil, fmt.Errorf("index %q included column %d: included columns cannot have a collation", name, j+1)
+		}
+		if exprF := col.FieldByName("Expr"); exprF.IsValid() && exprF.String() != "" {
+			return nil, fmt.Errorf("index %q included column %d: included columns cannot be expressions", name, j+1)
+		}
+		fname, err := columnField(col)
+		if err != nil {
+			return nil, fmt.Errorf("index %q included column %d: %w", name, j+1, err)
+		}
+		f := stmt.Schema.LookUpField(fname)
+		if f == nil || f.DBName == "" {
+			return nil, fmt.Errorf("index %q included column %d: field %q is not mapped to a column", name, j+1, fname)
+		}
+		if err := unmigrated(stmt, f); err != nil {
+			return nil, fmt.Errorf("index %q included column %d: %w", name, j+1, err)
+		}
+		for _, prev := range fields {
+			if prev == f {
+				return nil, fmt.Errorf("index %q: column %q is included more than once", name, f.DBName)
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// OpClassInfo describes an operator class: the access method it belongs
+// to, and the extension that provides it, if it is not builtin.
+type OpClassInfo struct {
+	Method    string // e.g. "gin"
+	Extension string // e.g. "pg_trgm", or "pg_trgm:1.6" to pin its version
+}
+
+// builtinOpClasses are the operator classes of PostgreSQL and its contrib modules. The
+// Method is set only for the ones that belong to a single access method, as the others,
+// e.g. text_pattern_ops (btree and hash), do not imply the method of their indexes.
+var builtinOpClasses = map[string]OpClassInfo{
+	"gin_trgm_ops":           {Method: "gin", Extension: "pg_trgm"},
+	"gist_trgm_ops":          {Method: "gist", Extension: "pg_trgm"},
+	"jsonb_path_ops":         {Method: "gin"},
+	"gin_hstore_ops":         {Method: "gin", Extension: "hstore"},
+	"gist_hstore_ops":        {Method: "gist", Extension: "hstore"},
+	"quad_point_ops":         {Method: "spgist"},
+	"kd_point_ops":           {Method: "spgist"},
+	"int4_minmax_ops":        {Method: "brin"},
+	"int8_minmax_ops":        {Method: "brin"},
+	"numeric_minmax_ops":     {Method: "brin"},
+	"date_minmax_ops":        {Method: "brin"},
+	"timestamp_minmax_ops":   {Method: "brin"},
+	"timestamptz_minmax_ops": {Method: "brin"},
+	"uuid_minmax_ops":        {Method: "brin"},
+	"text_minmax_ops":        {Method: "brin"},
+	"box_inclusion_ops":      {Method: "brin"},
+	"inet_inclusion_ops":     {Method: "brin"},
+	"range_inclusion_ops":    {Method: "brin"},
+	"text_pattern_ops":       {},
+	"varchar_pattern_ops":    {},
+	"bpchar_pattern_ops":     {},
+	"text_ops":               {},
+	"varchar_ops":            {},
+	"bpchar_ops":             {},
+	"int2_ops":               {},
+	"int4_ops":               {},
+	"int8_ops":               {},
+	"float4_ops":             {},
+	"float8_ops":             {},
+	"numeric_ops":            {},
+	"bool_ops":               {},
+	"date_ops":               {},
+	"timestamp_ops":          {},
+	"timestamptz_ops":        {},
+	"uuid_ops":               {},
+	"bytea_ops":              {},
+	"jsonb_ops":              {},
+	"array_ops":              {},
+	"tsvector_ops":           {},
+	"tsquery_ops":            {},
+	"inet_ops":               {},
+	"range_ops":              {},
+	"multirange_ops":         {},
+	"box_ops":                {},
+	"point_ops":              {},
+	"poly_ops":               {},
+	"circle_ops":             {},
+}
+
+// nullsNotDistinct reports whether the given Unique definition is rendered with NULLS NOT DISTINCT,
+// which is supported only by PostgreSQL. SQL Server implies it, and other dialects ignore it (the
+// loader warns about it), or report it as an error in strict mode (see WithStrictNullsNotDistinct).
+func nullsNotDistinct(stmt *gorm.Statement, name string, def reflect.Value) (bool, error) {
+	if f := def.FieldByName("NullsNotDistinct"); !f.IsValid() || !f.Bool() {
+		return false, nil
+	}
+	d, _ := tablesOf(stmt.DB)
+	switch dialect := stmt.DB.Dialector.Name(); {
+	case !def.FieldByName("Unique").Bool():
+		return false, fmt.Errorf("index %q: NullsNotDistinct requires a Unique definition", name)
+	case dialect == "postgres":
+		return true, nil
+	case dialect != "sqlserver" && d.strictNulls:
+		return false, fmt.Errorf("index %q: NULLS NOT DISTINCT is supported only by PostgreSQL and SQL Server", name)
+	}
+	return false, nil
+}
+
+// opClassParams returns the parameters of the operator class of the given column, rendered as the
+// content of their parenthesized list, or an empty string if it has none.
+func opClassParams(col reflect.Value) (string, error) {
+	paramsF := col.FieldByName("OpClassParams")
+	if !paramsF.IsValid() || paramsF.Len() == 0 {
+		return "", nil
+	}
+	if strings.TrimSpace(col.FieldByName("OpClass").String()) == "" {
+		return "", fmt.Errorf("operator class parameters require an operator class")
+	}
+	params := make(map[string]string, paramsF.Len())
+	for it := paramsF.MapRange(); it.Next(); {
+		k, v := strings.ToLower(strings.TrimSpace(it.Key().String())), strings.TrimSpace(it.Value().String())
+		if !storageParam.MatchString(k) || !storageParam.MatchString(v) {
+			return "", fmt.Errorf("invalid operator class parameter %s=%s", k, v)
+		}
+		params[k] = v
+	}
+	kvs := make([]string, 0, len(params))
+	for _, k := range slices.Sorted(maps.Keys(params)) {
+		kvs = append(kvs, k+"="+params[k])
+	}
+	return strings.Join(kvs, ", "), nil
+}
+
+// checkOpClass reports the operator classes of PostgreSQL index columns that are neither known
+// nor qualified with their schema (hence, assumed to be custom), as they are likely misspelled,
+// e.g. "gin_trgm_op". They are logged as warnings, or returned as errors in strict mode.
+func checkOpClass(stmt *gorm.Statement, name string, j int, opclass string) error {
+	if opclass == "" || strings.Contains(opclass, ".") || stmt.DB.Dialector.Name() != "postgres" {
+		return nil
+	}
+	if _, ok := knownOpClasses(stmt.DB)[strings.ToLower(opclass)]; ok {
+		return nil
+	}
+	if d, ok := tablesOf(stmt.DB); ok && d.strictOpClasses {
+		return fmt.Errorf("index %q column %d: unknown operator class %q", name, j+1, opclass)
+	}
+	stmt.DB.Logger.Warn(context.Background(), "index %q column %d: unknown operator class %q, register custom operator classes using WithKnownOpClasses", name, j+1, opclass)
+	return nil
+}
+
+// UniqueConstraints returns the column sets of the unique constraints and indexes generated for
+// the given models, keyed by their table, e.g. {"users": ["email", "tenant_id,handle"]}. The column
+// sets are comma-separated, hence they can be used as the conflict target of an upsert, e.g.
+// ON CONFLICT (tenant_id,handle). Primary keys and partial unique indexes are not returned. The
+// models are parsed using the dialect, naming strategy and schema of the loader, and the errors
+// of their definitions are returned.
+func (l *Loader) UniqueConstraints(models ...any) (map[string][]string, error) {
+	db, err := l.parseSession(models)
+	if err != nil {
+		return nil, err
+	}
+	sets := make(map[string][]string)
+	for _, model := range models {
+		if _, ok := model.(ViewDefiner); ok || model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+			continue
+		}
+		table, cols, err := uniqueColumns(db, model)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cols {
+			if !slices.Contains(sets[table], c) {
+				sets[table] = append(sets[table], c)
+			}
+		}
+		slices.Sort(sets[table])
+	}
+	return sets, nil
+}
+
+// DependencyGraph returns the tables that the table of each of the given models references using
+// foreign keys, derived from the associations of the models, e.g. {"pets": ["users"]}. Join tables
+// of many-to-many associations are included. Circular dependencies are not an error: they are
+// represented as tables that reference each other (or themselves), hence tools can report them.
+// Tables are named by the naming strategy and schema of the loader.
+func (l *Loader) DependencyGraph(models ...any) (map[string][]string, error) {
+	db, err := l.parseSession(models)
+	if err != nil {
+		return nil, err
+	}
+	graph := make(map[string][]string)
+	add := func(c *schema.Constraint) {
+		if c == nil || c.Schema == nil || c.ReferenceSchema == nil {
+			return
+		}
+		if deps := graph[c.Schema.Table]; !slices.Contains(deps, c.ReferenceSchema.Table) {
+			graph[c.Schema.Table] = append(deps, c.ReferenceSchema.Table)
+		}
+	}
+	for _, model := range models {
+		if _, ok := model.(ViewDefiner); ok || model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+			continue
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		if _, ok := graph[stmt.Schema.Table]; !ok {
+			graph[stmt.Schema.Table] = []string{}
+		}
+		for _, rel := range stmt.Schema.Relationships.Relations {
+			add(rel.ParseConstraint())
+			if rel.JoinTable != nil {
+				if _, ok := graph[rel.JoinTable.Table]; !ok {
+					graph[rel.JoinTable.Table] = []string{}
+				}
+				for _, jrel := range rel.JoinTable.Relationships.Relations {
+					add(jrel.ParseConstraint())
+				}
+			}
+		}
+	}
+	for _, deps := range graph {
+		slices.Sort(deps)
+	}
+	return graph, nil
+}
+
+// ResolvedIndex is an index of a model, as resolved from its Indexes() definitions (see DescribeIndexes).
+type ResolvedIndex struct {
+	Name             string           `json:"name"`
+	Table            string           `json:"table"`
+	Columns          []ResolvedColumn `json:"columns"`
+	Include          []string         `json:"include,omitempty"`
+	Unique           bool             `json:"unique,omitempty"`
+	Type             string           `json:"type,omitempty"`
+	Where            string           `json:"where,omitempty"`
+	Extensions       []string         `json:"extensions,omitempty"`
+	NullsNotDistinct bool             `json:"nulls_not_distinct,omitempty"`
+}
+
+// ResolvedColumn is a key column of a ResolvedIndex. Name is the name of the column, or empty for
+// expressions, whose references are replaced by their quoted column names in Expr.
+type ResolvedColumn struct {
+	Name          string            `json:"name,omitempty"`
+	Expr          string            `json:"expr,omitempty"`
+	Sort          string            `json:"sort,omitempty"`
+	Nulls         string            `json:"nulls,omitempty"`
+	OpClass       string            `json:"opclass,omitempty"`
+	OpClassParams map[string]string `json:"opclass_params,omitempty"`
+	Collation     string            `json:"collation,omitempty"`
+	Length        int               `json:"length,omitempty"`
+}
+
+// DescribeIndexes returns the indexes resolved from the Indexes() definitions of the given model,
+// in the order of their definitions, as they are created by Load for the dialect of the loader:
+// names with their placeholders replaced, column names of the selected fields, the predicates of
+// Where and Conds, access methods (including the inferred ones), the text search vectors of
+// fulltext indexes on PostgreSQL, and the extensions of their operator classes (including the
+// ones of WithKnownOpClasses) with their pinned versions. The definitions are validated the same
+// way as by Load. Unique constraints (see Style) are not returned, see UniqueConstraints.
+func (l *Loader) DescribeIndexes(model any) ([]ResolvedIndex, error) {
+	if model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("model must be a struct or *struct, got %T", model)
+	}
+	defs, ok := indexDefinitions(receiver(model))
+	if !ok {
+		return nil, nil
+	}
+	db, err := l.parseSession([]any{model})
+	if err != nil {
+		return nil, err
+	}
+	tx, value, err := migrationTarget(db, model)
+	if err != nil {
+		return nil, err
+	}
+	stmt, target := &gorm.Statement{DB: db}, &gorm.Statement{DB: tx}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	if err := target.Parse(value); err != nil {
+		return nil, err
+	}
+	parsed := target.Schema.ParseIndexes()
+	classes := knownOpClasses(db)
+	required, _, err := requiredExtensions(l.extensions, []any{model}, classes)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []ResolvedIndex
+	for _, def := range defs {
+		def = reflect.Indirect(def)
+		if def.FieldByName("Style").String() != "" {
+			continue
+		}
+		name := contentName(db, stmt.Schema, def)
+		idx, ok := parsed[name]
+		if !ok {
+			return nil, fmt.Errorf("index %q was not resolved", name)
+		}
+		ri := ResolvedIndex{
+			Name:   name,
+			Table:  stmt.Schema.Table,
+			Unique: idx.Class == "UNIQUE",
+			Type:   idx.Type,
+			Where:  strings.ReplaceAll(idx.Where, tagSemicolon, ";"),
+		}
+		if ri.NullsNotDistinct, err = nullsNotDistinct(stmt, name, def); err != nil {
+			return nil, err
+		}
+		// MySQL creates fulltext indexes of the FULLTEXT class, rather than of an access method.
+		if idx.Class == "FULLTEXT" {
+			ri.Type = "fulltext"
+		}
+		cols := def.FieldByName("Columns")
+		// Fulltext indexes of PostgreSQL index a single text search vector of their columns.
+		if strings.EqualFold(strings.TrimSpace(def.FieldByName("Type").String()), "fulltext") {
+			if cols, err = fulltextColumns(stmt, name, ri.Unique, cols); err != nil {
+				return nil, err
+			}
+		}
+		for j := 0; j < cols.Len(); j++ {
+			col := reflect.Indirect(cols.Index(j))
+			rc := ResolvedColumn{
+				Sort:      strings.ToLower(strings.TrimSpace(col.FieldByName("Sort").String())),
+				Nulls:     strings.ToLower(strings.TrimSpace(col.FieldByName("Nulls").String())),
+				OpClass:   strings.TrimSpace(col.FieldByName("OpClass").String()),
+				Collation: strings.TrimSpace(col.FieldByName("Collation").String()),
+				Length:    int(col.FieldByName("Length").Int()),
+			}
+			if params := col.FieldByName("OpClassParams").Interface().(map[string]string); len(params) > 0 {
+				rc.OpClassParams = maps.Clone(params)
+			}
+			if strings.TrimSpace(col.FieldByName("Expr").String()) != "" {
+				if _, rc.Expr, err = exprColumn(stmt, col); err != nil {
+					return nil, fmt.Errorf("index %q column %d: %w", name, j+1, err)
+				}
+			} else {
+				fname, err := columnField(col)
+				if err != nil {
+					return nil, fmt.Errorf("index %q column %d: %w", name, j+1, err)
+				}
+				rc.Name = stmt.Schema.LookUpField(fname).DBName
+			}
+			// Extensions are reported with the versions they are created with (see requiredExtensions).
+			if ext, _ := parseExtension(classes[strings.ToLower(rc.OpClass)].Extension); ext.name != "" {
+				if k := slices.IndexFunc(required, func(e extension) bool { return e.name == ext.name }); k != -1 && !slices.Contains(ri.Extensions, required[k].String()) {
+					ri.Extensions = append(ri.Extensions, required[k].String())
+				}
+			}
+			ri.Columns = append(ri.Columns, rc)
+		}
+		include, err := includeColumns(stmt, name, def.FieldByName("Include"))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range include {
+			ri.Include = append(ri.Include, f.DBName)
+		}
+		indexes = append(indexes, ri)
+	}
+	return indexes, nil
+}
+
+// parseSession returns a session of the loader for parsing the given models outside of Load, that
+// names their tables the same way as Load does. It never executes statements.
+func (l *Loader) parseSession(models []any) (*gorm.DB, error) {
+	di, err := l.dialector()
+	if err != nil {
+		return nil, err
+	}
+	cfg := detach(l.config)
+	l.configure(&cfg)
+	cfg.DryRun = true
+	db, err := gorm.Open(l.tablesDialector(di), &cfg)
+	if err != nil {
+		return nil, err
+	}
+	if l.schema != "" {
+		var tables []any
+		for _, model := range models {
+			if _, ok := model.(ViewDefiner); !ok && model != nil && indirectType(reflect.TypeOf(model)).Kind() == reflect.Struct {
+				tables = append(tables, model)
+			}
+		}
+		if err := l.qualifyTablers(tables, db); err != nil {
+			return nil, err
+		}
+	}
+	for _, cb := range l.beforeAutoMigrate {
+		if err := cb(db); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// uniqueColumns returns the table of the model and the comma-separated column sets of its
+// unique columns, unique indexes and unique constraints (see UniqueConstraints).
+func uniqueColumns(db *gorm.DB, model any) (string, []string, error) {
+	tx, value, err := migrationTarget(db, model)
+	if err != nil {
+		return "", nil, err
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(value); err != nil {
+		return "", nil, err
+	}
+	// The table of the clone is not qualified with its schema (see WithSchema).
+	orig := &gorm.Statement{DB: db}
+	if err := orig.Parse(model); err != nil {
+		return "", nil, err
+	}
+	table := orig.Schema.Table
+	var sets []string
+	for _, f := range stmt.Schema.Fields {
+		if f.Unique && !f.PrimaryKey && f.DBName != "" {
+			sets = append(sets, f.DBName)
+		}
+	}
+	for _, idx := range stmt.Schema.ParseIndexes() {
+		if idx.Class != "UNIQUE" || idx.Where != "" {
+			continue
+		}
+		cols := make([]string, 0, len(idx.Fields))
+		for _, f := range idx.Fields {
+			if f.Expression != "" {
+				cols = nil
+				break
+			}
+			cols = append(cols, f.DBName)
+		}
+		if len(cols) > 0 {
+			sets = append(sets, strings.Join(cols, ","))
+		}
+	}
+	cs, err := uniqueConstraints(db, model)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, c := range cs {
+		sets = append(sets, strings.Join(c.columns, ","))
+	}
+	return table, sets, nil
+}
+
+// ExtractRequiredExtensions returns the extensions that Load creates for the given models on
+// PostgreSQL: the ones of WithExtensions in their order, followed by the ones required by the
+// models, sorted by their names. The models require the extensions returned by their
+// RequiredExtensions() []string method, and the ones that provide the operator classes used by
+// their Indexes(), including the ones registered using WithKnownOpClasses. Hence, the order does
+// not depend on the order of the models or of their definitions. Extensions can be pinned to a
+// version using the "name:version" form, e.g. "pg_trgm:1.6", which is returned as-is.
+// Conflicting versions of an extension are returned as an error.
+func (l *Loader) ExtractRequiredExtensions(models ...any) ([]string, error) {
+	classes := l.opClasses
+	if classes == nil {
+		classes = builtinOpClasses
+	}
+	required, _, err := requiredExtensions(l.extensions, models, classes)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(required))
+	for i, ext := range required {
+		names[i] = ext.String()
+	}
+	return names, nil
+}
+
+// extension is an extension required by the models, optionally pinned to a version.
+type extension struct {
+	name, version string
+}
+
+// extVersion matches the versions of extensions, e.g. "1.6" or "3.4.0dev".
+var extVersion = regexp.MustCompile(`^[\w.-]+$`)
+
+// parseExtension parses an extension of the form "name" or "name:version", e.g. "pg_trgm:1.6".
+func parseExtension(s string) (extension, error) {
+	name, version, _ := strings.Cut(s, ":")
+	ext := extension{name: strings.TrimSpace(name), version: strings.TrimSpace(version)}
+	if ext.version != "" && !extVersion.MatchString(ext.version) {
+		return ext, fmt.Errorf("extension %q: invalid version %q", ext.name, ext.version)
+	}
+	return ext, nil
+}
+
+func (e extension) String() string {
+	if e.version == "" {
+		return e.name
+	}
+	return e.name + ":" + e.version
+}
+
+// requiredExtensions returns the given extensions in their order, followed by the extensions
+// required by the models, using the given operator classes, sorted by their names. Duplicate
+// extensions are returned once, with their version, if any. The names of the indexes that require
+// each extension for their operator classes are returned as well, keyed by the extension names.
+// The first error, e.g. of conflicting versions of an extension, is returned with the extensions.
+func requiredExtensions(exts []string, models []any, classes map[string]OpClassInfo) ([]extension, map[string][]string, error) {
+	var (
+		required []extension
+		indexes  = make(map[string][]string)
+		err      error
+	)
+	add := func(s string) string {
+		ext, perr := parseExtension(s)
+		i := slices.IndexFunc(required, func(e extension) bool { return e.name == ext.name })
+		switch {
+		case perr != nil:
+			err = cmp.Or(err, perr)
+		case ext.name == "":
+		case i == -1:
+			required = append(required, ext)
+		case required[i].version == "":
+			required[i].version = ext.version
+		case ext.version != "" && ext.version != required[i].version:
+			err = cmp.Or(err, fmt.Errorf("extension %q is required with versions %s and %s", ext.name, required[i].version, ext.version))
+		}
+		return ext.name
+	}
+	for _, ext := range exts {
+		add(ext)
+	}
+	explicit := len(required)
+	for _, model := range models {
+		if model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+			continue
+		}
+		if r, ok := receiver(model).Interface().(interface{ RequiredExtensions() []string }); ok {
+			for _, ext := range r.RequiredExtensions() {
+				add(ext)
+			}
+		}
+		defs, ok := indexDefinitions(receiver(model))
+		if !ok {
+			continue
+		}
+		for _, def := range defs {
+			def = reflect.Indirect(def)
+			cols := def.FieldByName("Columns")
+			for j := 0; cols.IsValid() && j < cols.Len(); j++ {
+				opF := reflect.Indirect(cols.Index(j)).FieldByName("OpClass")
+				if !opF.IsValid() {
+					continue
+				}
+				ext := strings.TrimSpace(classes[strings.ToLower(strings.TrimSpace(opF.String()))].Extension)
+				if ext == "" {
+					continue
+				}
+				ext = add(ext)
+				if name := def.FieldByName("Name").String(); !slices.Contains(indexes[ext], name) {
+					indexes[ext] = append(indexes[ext], name)
+				}
+			}
+		}
+	}
+	// The extensions of the models are sorted, as the order of the models is arbitrary.
+	slices.SortFunc(required[explicit:], func(a, b extension) int { return strings.Compare(a.name, b.name) })
+	return required, indexes, err
 }
 
-// AutoMigrateModel inspects 'model' for an Indexes() method.
-// If present, it uses those definitions to synthesize index tags on a
-// cloned runtime type, then runs AutoMigrate on that clone.
-// If not, it falls back to db.AutoMigrate(model).
-func AutoMigrateModel(db *gorm.DB, model any) error {
-	if model == nil {
-		return fmt.Errorf("nil model")
+// indexType returns the access method of an index. The Type applies to the whole index, and
+// it is inferred from the operator classes of its columns if unset. An error is returned if
+// the operator classes of the columns imply different methods, or a method other than Type.
+func indexType(name, typ string, cols reflect.Value, classes map[string]OpClassInfo) (string, error) {
+	var implied, impliedBy string
+	for j := 0; cols.IsValid() && j < cols.Len(); j++ {
+		opF := reflect.Indirect(cols.Index(j)).FieldByName("OpClass")
+		if !opF.IsValid() {
+			continue
+		}
+		opclass := strings.TrimSpace(opF.String())
+		info, ok := classes[strings.ToLower(opclass)]
+		m := info.Method
+		switch {
+		case !ok || m == "":
+		case implied == "":
+			implied, impliedBy = m, opclass
+		case implied != m:
+			return "", fmt.Errorf("index %q: operator classes %q and %q imply different access methods (%s and %s)", name, impliedBy, opclass, implied, m)
+		}
+	}
+	switch {
+	case implied == "":
+		return typ, nil
+	case typ == "":
+		return implied, nil
+	case !strings.EqualFold(typ, implied):
+		return "", fmt.Errorf("index %q: operator class %q implies access method %q, but Type is %q", name, impliedBy, implied, typ)
 	}
+	return typ, nil
+}
 
-	base := indirectType(reflect.TypeOf(model))
-	if base.Kind() != reflect.Struct {
-		return fmt.Errorf("model must be a struct or *struct, got %v", base.Kind())
+// accessMethods are the builtin index access methods of PostgreSQL.
+var accessMethods = []string{"btree", "hash", "gist", "gin", "spgist", "brin"}
+
+// checkAccessMethod validates the access method of a PostgreSQL index. Hash indexes support a
+// single key column and no uniqueness. In strict mode (see WithStrictAccessMethods), methods
+// other than the builtin ones and the ones of the known operator classes are reported as errors.
+func checkAccessMethod(stmt *gorm.Statement, name, typ string, def reflect.Value) error {
+	if typ == "" || stmt.DB.Dialector.Name() != "postgres" {
+		return nil
+	}
+	method := strings.ToLower(typ)
+	if method == "hash" {
+		n := def.FieldByName("Columns").Len()
+		if inc := def.FieldByName("Include"); inc.IsValid() {
+			n += inc.Len()
+		}
+		switch {
+		case n > 1:
+			return fmt.Errorf("index %q: hash indexes support a single column, but it has %d", name, n)
+		case def.FieldByName("Unique").Bool():
+			return fmt.Errorf("index %q: hash indexes cannot be unique", name)
+		}
+	}
+	if d, ok := tablesOf(stmt.DB); !ok || !d.strictMethods || slices.Contains(accessMethods, method) {
+		return nil
+	}
+	for _, info := range knownOpClasses(stmt.DB) {
+		if strings.EqualFold(info.Method, method) {
+			return nil
+		}
 	}
+	return fmt.Errorf("index %q: unknown access method %q, expected one of %s, or the method of an operator "+
+		"class registered using WithKnownOpClasses", name, typ, strings.Join(accessMethods, ", "))
+}
 
-	// Find Indexes method on a *pointer* receiver if needed.
-	mv := reflect.ValueOf(model)
-	var recv reflect.Value
-	if mv.Kind() == reflect.Ptr {
-		recv = mv
-	} else {
-		// create addressable copy to access pointer-receiver methods
-		p := reflect.New(mv.Type())
-		p.Elem().Set(mv)
-		recv = p
+// fulltextColumns validates the columns of a fulltext index, and returns the columns to index. On
+// PostgreSQL, plain columns are indexed as a single text search vector (see TSVector) using the
+// simple configuration, while text search vectors (expression columns) are indexed as-is.
+func fulltextColumns(stmt *gorm.Statement, name string, unique bool, cols reflect.Value) (reflect.Value, error) {
+	dialect := stmt.DB.Dialector.Name()
+	switch {
+	case dialect != "mysql" && dialect != "postgres":
+		return cols, fmt.Errorf("index %q: fulltext indexes are supported only by MySQL and PostgreSQL, not %s", name, dialect)
+	case unique:
+		return cols, fmt.Errorf("index %q: fulltext indexes cannot be unique", name)
 	}
+	var exprs int
+	for j := 0; j < cols.Len(); j++ {
+		col := reflect.Indirect(cols.Index(j))
+		for _, f := range []string{"Sort", "Nulls", "OpClass", "Collation"} {
+			if col.FieldByName(f).String() != "" {
+				return cols, fmt.Errorf("index %q column %d: fulltext columns cannot have %s", name, j+1, strings.ToLower(f))
+			}
+		}
+		if col.FieldByName("Expr").String() != "" {
+			exprs++
+		}
+	}
+	switch {
+	case dialect == "mysql" && exprs > 0:
+		return cols, fmt.Errorf("index %q: fulltext indexes of MySQL cannot have expression columns", name)
+	case dialect == "mysql" || exprs == cols.Len():
+		return cols, nil
+	case exprs > 0:
+		return cols, fmt.Errorf("index %q: fulltext indexes cannot mix plain and expression columns", name)
+	}
+	vec := reflect.New(cols.Type().Elem()).Elem()
+	refs := reflect.MakeSlice(vec.FieldByName("Refs").Type(), 0, cols.Len())
+	for j := 0; j < cols.Len(); j++ {
+		col := reflect.Indirect(cols.Index(j))
+		if _, err := columnField(col); err != nil {
+			return cols, fmt.Errorf("index %q column %d: %w", name, j+1, err)
+		}
+		refs = reflect.Append(refs, columnSelector(col))
+	}
+	vec.FieldByName("Expr").SetString(tsvectorExpr("simple", cols.Len()))
+	vec.FieldByName("Refs").Set(refs)
+	out := reflect.MakeSlice(cols.Type(), 0, 1)
+	return reflect.Append(out, vec), nil
+}
 
-	method := recv.MethodByName("Indexes")
-	if !method.IsValid() {
-		// No Indexes() -> regular migration
-		return db.AutoMigrate(model)
+// opClassName matches operator class names, optionally qualified with
+// their schema, e.g. "gin_trgm_ops" or "app.custom_ops".
+var opClassName = regexp.MustCompile(`^[A-Za-z_][\w$]*(\.[A-Za-z_][\w$]*)?$`)
+
+// collationName matches collation names, optionally qualified with their schema, e.g. "en-US-x-icu".
+var collationName = regexp.MustCompile(`^[A-Za-z_][\w-]*(\.[A-Za-z_][\w-]*)?$`)
+
+// sqliteCollations are the built-in collations of SQLite.
+var sqliteCollations = []string{"BINARY", "NOCASE", "RTRIM"}
+
+// collateClause returns the COLLATE clause of the given collation. Collations are case-sensitive
+// identifiers on PostgreSQL, hence they are quoted.
+func collateClause(stmt *gorm.Statement, collation string) (string, error) {
+	if !collationName.MatchString(collation) {
+		return "", fmt.Errorf("invalid collation %q", collation)
 	}
-	if method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
-		// Unexpected signature; ignore gracefully.
-		return db.AutoMigrate(model)
+	switch stmt.DB.Dialector.Name() {
+	case "postgres":
+		return "COLLATE " + stmt.Quote(collation), nil
+	case "sqlserver":
+		return "", fmt.Errorf("collations of index columns are not supported by sqlserver")
+	case "sqlite":
+		if !slices.Contains(sqliteCollations, strings.ToUpper(collation)) {
+			return "", fmt.Errorf("collation %q is not supported by sqlite, expected BINARY, NOCASE or RTRIM", collation)
+		}
+		return "COLLATE " + strings.ToUpper(collation), nil
 	}
+	return "COLLATE " + collation, nil
+}
 
-	// Call Indexes() reflectively; result is a slice of IndexDefinition[T] (unknown T).
-	out := method.Call(nil)[0]
-	if out.Kind() != reflect.Slice {
-		return db.AutoMigrate(model)
+// sortOrder returns the ordering of an index column, e.g. "desc nulls last".
+func sortOrder(sort, nulls string) string {
+	var order []string
+	if s := strings.TrimSpace(sort); s != "" {
+		order = append(order, s)
 	}
-	if out.Len() == 0 {
-		return db.AutoMigrate(model)
+	if n := strings.TrimSpace(nulls); n != "" {
+		order = append(order, "nulls "+n)
 	}
+	return strings.Join(order, " ")
+}
 
-	// Build field -> index-tag fragments from the returned definitions.
-	fieldToIndexTags, err := collectIndexTagsFromIndexesValue(base, out)
+// dataTypeOf returns the column type of the field in the dialect of the given session.
+func dataTypeOf(db *gorm.DB, f *schema.Field) string {
+	if m, ok := db.Migrator().(interface{ DataTypeOf(*schema.Field) string }); ok {
+		return m.DataTypeOf(f)
+	}
+	return db.Dialector.DataTypeOf(f)
+}
+
+// ginIndexable reports whether the given PostgreSQL type has a default operator class for gin.
+func ginIndexable(typ string) bool {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	switch {
+	case strings.HasSuffix(typ, "[]"), strings.HasPrefix(typ, "_"):
+		return true
+	case typ == "jsonb", typ == "tsvector", typ == "hstore":
+		return true
+	default:
+		return false
+	}
+}
+
+// unmigrated returns an error in strict mode (see WithStrictIndexColumns) if the column of the
+// given field is not created by GORM, e.g. the column of a field tagged `gorm:"-:migration"`.
+func unmigrated(stmt *gorm.Statement, f *schema.Field) error {
+	if d, ok := tablesOf(stmt.DB); ok && d.strictColumns && f.IgnoreMigration {
+		return fmt.Errorf("column %q is not created in table %s", f.DBName, stmt.Schema.Table)
+	}
+	return nil
+}
+
+// condPredicate renders the given Cond[T] value as a predicate of the loader dialect.
+func condPredicate(stmt *gorm.Statement, cond reflect.Value) (string, error) {
+	fname, err := fieldNameFromSelectorValue(cond.FieldByName("Sel"))
 	if err != nil {
-		return err
+		return "", err
+	}
+	f := stmt.Schema.LookUpField(fname)
+	if f == nil || f.DBName == "" {
+		return "", fmt.Errorf("field %q is not mapped to a column", fname)
+	}
+	v := cond.FieldByName("Value")
+	if v.IsNil() {
+		return stmt.Quote(f.DBName) + " IS NULL", nil
 	}
+	lit, err := sqlLiteral(stmt.DB.Dialector.Name(), v.Elem())
+	if err != nil {
+		return "", err
+	}
+	return stmt.Quote(f.DBName) + " = " + lit, nil
+}
 
-	// Build cloned struct type with merged tags.
-	fields := make([]reflect.StructField, 0, base.NumField())
-	for i := 0; i < base.NumField(); i++ {
-		sf := base.Field(i)
-		// Keep only exported fields; GORM ignores unexported columns anyway.
-		if sf.PkgPath != "" {
+// qualifiedColumn matches column references qualified with a table, e.g. "t.col" or `"t"."col"`.
+var qualifiedColumn = regexp.MustCompile(`(?:^|[^\w.])["[` + "`" + `]?([A-Za-z_]\w*)["\]` + "`" + `]?\.["[` + "`" + `]?([A-Za-z_]\w*)`)
+
+// stringLiteral matches SQL string literals.
+var stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// foreignReference returns the first column reference of the given predicate
+// that is qualified with a table other than the indexed one, if any. Calls of
+// functions qualified with their schema, e.g. pg_catalog.lower(name), are skipped.
+func foreignReference(table, where string) (string, bool) {
+	// The table might be qualified with its schema (see WithSchema).
+	if i := strings.LastIndexByte(table, '.'); i != -1 {
+		table = table[i+1:]
+	}
+	where = stringLiteral.ReplaceAllString(where, "''")
+	for _, m := range qualifiedColumn.FindAllStringSubmatchIndex(where, -1) {
+		qualifier, column := where[m[2]:m[3]], where[m[4]:m[5]]
+		if rest := strings.TrimLeft(where[m[1]:], "\"]` \t\n"); strings.HasPrefix(rest, "(") {
 			continue
 		}
-		newTag := mergeIndexIntoGormTag(sf.Tag, fieldToIndexTags[sf.Name])
-		fields = append(fields, reflect.StructField{
-			Name:      sf.Name,
-			Type:      sf.Type,
-			Tag:       newTag,
-			Anonymous: sf.Anonymous,
-		})
+		if !strings.EqualFold(qualifier, table) {
+			return qualifier + "." + column, true
+		}
 	}
+	return "", false
+}
 
-	dyn := reflect.StructOf(fields)
-	ptr := reflect.New(dyn).Interface()
+// volatileCall matches calls of the known functions that are not immutable, and the SQL
+// value functions that are called without parentheses, e.g. current_timestamp.
+var volatileCall = regexp.MustCompile(`(?i)\b(?:(now|clock_timestamp|statement_timestamp|transaction_timestamp|timeofday|` +
+	`random|gen_random_uuid|uuid_generate_v1|uuid_generate_v4|nextval|currval|lastval|setval|txid_current|` +
+	`pg_backend_pid|current_setting)\s*\(|(current_timestamp|current_date|current_time|` +
+	`localtime|localtimestamp|current_user|session_user|current_role)\b)`)
 
-	// Respect custom table name if model implements Tabler.
-	if tabler, ok := any(model).(schema.Tabler); ok {
-		return db.Table(tabler.TableName()).AutoMigrate(ptr)
+// volatileFunc returns the first function of the predicate that is not immutable, if any. As
+// the rows of a unique partial index depend on its predicate, the predicate must be immutable.
+func volatileFunc(where string) (string, bool) {
+	m := volatileCall.FindStringSubmatch(stringLiteral.ReplaceAllString(where, "''"))
+	switch {
+	case m == nil:
+		return "", false
+	case m[1] != "":
+		return strings.ToLower(m[1]) + "()", true
+	default:
+		return strings.ToLower(m[2]), true
 	}
-	// Also handle pointer-receiver TableName() methods by asserting on *T when model is T.
-	mt := reflect.TypeOf(model)
-	var ptrModel any
-	if mt.Kind() == reflect.Ptr {
-		ptrModel = model
-	} else {
-		ptrModel = reflect.New(mt).Interface()
+}
+
+var (
+	// subquery matches the subqueries of predicates.
+	subquery = regexp.MustCompile(`(?i)\(\s*SELECT\b`)
+	// filterNotNull matches the IS NOT NULL tests, which are supported by filtered indexes.
+	filterNotNull = regexp.MustCompile(`(?i)\bIS\s+NOT\s+NULL\b`)
+	// filterUnsupported matches the operators and calls that filtered indexes do not support.
+	filterUnsupported = regexp.MustCompile(`(?i)\b(?:OR|LIKE|BETWEEN|NOT|EXISTS|SELECT)\b|(?:=|<>|!=)\s*NULL\b|\b\w+\s*\(`)
+)
+
+// checkPredicate validates the predicate of a partial index against the rules of the loader
+// dialect: MySQL does not support partial indexes, SQLite requires deterministic predicates
+// without subqueries, and the filtered indexes of SQL Server support only comparisons, IN and
+// IS [NOT] NULL tests, combined with AND.
+func checkPredicate(stmt *gorm.Statement, name, where string) error {
+	if where == "" {
+		return nil
 	}
-	if tabler, ok := ptrModel.(schema.Tabler); ok {
-		return db.Table(tabler.TableName()).AutoMigrate(ptr)
+	bare := stringLiteral.ReplaceAllString(where, "''")
+	switch dialect := stmt.DB.Dialector.Name(); dialect {
+	case "mysql":
+		return fmt.Errorf("index %q: partial indexes are not supported by %s", name, dialect)
+	case "sqlite":
+		if fn, ok := volatileFunc(where); ok {
+			return fmt.Errorf("index %q: where of a partial index must be deterministic on sqlite, but calls %s", name, fn)
+		}
+		if subquery.MatchString(bare) {
+			return fmt.Errorf("index %q: where of a partial index must not contain subqueries on sqlite", name)
+		}
+	case "sqlserver":
+		bare = filterNotNull.ReplaceAllString(bare, "IS NULL")
+		for _, m := range filterUnsupported.FindAllString(bare, -1) {
+			op := strings.ToUpper(strings.Join(strings.Fields(strings.TrimSuffix(m, "(")), " "))
+			if op == "IN" || op == "AND" {
+				continue
+			}
+			if strings.HasSuffix(m, "(") {
+				op += "()"
+			}
+			return fmt.Errorf("index %q: filtered indexes of sqlserver support only comparisons, IN and IS [NOT] NULL combined with AND, but where uses %s", name, op)
+		}
 	}
-	return db.AutoMigrate(ptr)
+	return nil
 }
 
-// -------- internals --------
+// predicateIdent matches the identifiers of predicates, quoted or not, with the cast operator or
+// the qualifier dot that precedes them, and the dot, parenthesis or quote that follows them.
+var predicateIdent = regexp.MustCompile("(::\\s*|\\.\\s*)?(?:\"([^\"]+)\"|`([^`]+)`|\\[([A-Za-z_][\\w ]*)\\]|\\b([A-Za-z_]\\w*))(\\s*[.('])?")
 
-func collectIndexTagsFromIndexesValue(baseStruct reflect.Type, defsSlice reflect.Value) (map[string][]string, error) {
-	fieldToIndexTags := map[string][]string{}
+// predicateWords are the keywords and type names of predicates that are not reserved.
+var predicateWords = wordSet(`ilike similar escape isnull notnull unknown glob regexp rlike div mod collate
+	interval at time zone precision varying with without some`)
 
-	for i := 0; i < defsSlice.Len(); i++ {
-		def := defsSlice.Index(i)
-		if def.Kind() == reflect.Pointer {
-			def = def.Elem()
+// checkPredicateColumns reports the identifiers of the predicate that are not columns of the
+// indexed table (see WithPredicateColumnWarnings). Function names, types, table qualifiers,
+// keywords and the prefixes of typed literals (e.g. DATE '2024-01-01') are skipped.
+func checkPredicateColumns(stmt *gorm.Statement, name, where string) error {
+	d, ok := tablesOf(stmt.DB)
+	if !ok || !d.predicateWarnings && !d.strictPredicates || where == "" {
+		return nil
+	}
+	columns := make(map[string]bool, len(stmt.Schema.DBNames))
+	for _, c := range stmt.Schema.DBNames {
+		columns[strings.ToLower(c)] = true
+	}
+	var prev string
+	for _, m := range predicateIdent.FindAllStringSubmatch(stringLiteral.ReplaceAllString(where, "''"), -1) {
+		ident, quoted := cmp.Or(m[2], m[3], m[4]), true
+		if ident == "" {
+			ident, quoted = m[5], false
 		}
-		if def.Kind() != reflect.Struct {
-			return nil, fmt.Errorf("Indexes()[%d] is not a struct", i)
+		lower := strings.ToLower(ident)
+		afterAs := prev == "as"
+		prev = lower
+		switch {
+		case strings.HasPrefix(m[1], "::"), m[6] != "", afterAs:
+			continue
+		case !quoted && (reservedWords[lower] || predicateWords[lower] || dialectReservedWords[stmt.DB.Dialector.Name()][lower]):
+			continue
+		case columns[lower]:
+			continue
+		}
+		if d.strictPredicates {
+			return fmt.Errorf("index %q: where references unknown column %q of table %s", name, ident, stmt.Schema.Table)
 		}
+		stmt.DB.Logger.Warn(context.Background(), "index %q: where references unknown column %q of table %s", name, ident, stmt.Schema.Table)
+	}
+	return nil
+}
 
-		// Expect fields: Name string, Columns []Col[?], Unique bool, Where string
-		nameF := def.FieldByName("Name")
-		colsF := def.FieldByName("Columns")
-		uniqueF := def.FieldByName("Unique")
-		whereF := def.FieldByName("Where")
+var bareColumn = regexp.MustCompile(`^(?i)(not\s+)?(\w+)$`)
 
-		if !nameF.IsValid() || !colsF.IsValid() || !uniqueF.IsValid() || !whereF.IsValid() {
-			return nil, fmt.Errorf("Indexes()[%d] doesn't look like IndexDefinition", i)
+// boolPredicate expands a predicate that is a bare boolean column (e.g. "is_active"
+// or "NOT is_active") to a comparison on dialects that do not support it.
+func boolPredicate(stmt *gorm.Statement, where string) string {
+	m := bareColumn.FindStringSubmatch(where)
+	if m == nil {
+		return where
+	}
+	f := stmt.Schema.LookUpField(m[2])
+	if f == nil || f.DBName == "" || f.DataType != schema.Bool {
+		return where
+	}
+	col := quoteReserved(stmt, m[2])
+	switch {
+	case stmt.DB.Dialector.Name() != "postgres":
+	case col == m[2]:
+		return where
+	case m[1] != "":
+		return "NOT " + col
+	default:
+		return col
+	}
+	if m[1] != "" {
+		return col + " = 0"
+	}
+	return col + " = 1"
+}
+
+// quoteReserved quotes the given column name if it is a reserved word of the
+// loader dialect, as GORM quotes only the identifiers it renders itself.
+func quoteReserved(stmt *gorm.Statement, name string) string {
+	lower := strings.ToLower(name)
+	if reservedWords[lower] || dialectReservedWords[stmt.DB.Dialector.Name()][lower] {
+		return stmt.Quote(name)
+	}
+	return name
+}
+
+// reservedWords are the keywords reserved by all supported dialects.
+var reservedWords = wordSet(`all and any as asc between by case check column constraint create cross
+	default delete desc distinct drop else exists false for foreign from full grant group having in index
+	inner insert into is join left like not null on or order outer primary references right select set
+	table then to true union unique update using values when where with`)
+
+// dialectReservedWords are the keywords reserved by a single dialect.
+var dialectReservedWords = map[string]map[string]bool{
+	"postgres":  wordSet(`analyse analyze array collate current_date current_time current_timestamp current_user do fetch limit offset only placing returning session_user user variadic window`),
+	"mysql":     wordSet(`change condition database databases div interval key keys kill limit lock match mod rank range read reads release rename repeat replace require row rows schema separator show signal usage write`),
+	"sqlite":    wordSet(`abort autoincrement collate escape glob limit notnull offset regexp temp temporary`),
+	"sqlserver": wordSet(`backup browse clustered compute contains current database file fillfactor identity key open percent pivot plan proc public rule schema top tran unpivot user view`),
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// sqlLiteral renders the given value as an SQL literal of the dialect.
+func sqlLiteral(dialect string, v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		if dialect == "mysql" {
+			// MySQL treats backslashes in string literals as escape characters.
+			s = strings.ReplaceAll(s, `\`, `\\`)
 		}
-		name := nameF.String()
-		unique := uniqueF.Bool()
-		where := strings.TrimSpace(whereF.String())
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'", nil
+	case reflect.Bool:
+		switch {
+		case dialect == "postgres":
+			return strconv.FormatBool(v.Bool()), nil
+		case v.Bool():
+			return "1", nil
+		default:
+			return "0", nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported literal type %s", v.Type())
+	}
+}
 
-		if colsF.Kind() != reflect.Slice {
-			return nil, fmt.Errorf("Index %q: Columns is not a slice", name)
+// softDeleteColumn returns the column name of the gorm.DeletedAt field of the schema.
+func softDeleteColumn(sch *schema.Schema) (string, bool) {
+	for _, f := range sch.Fields {
+		if f.DBName != "" && indirectType(f.FieldType) == deletedAtType {
+			return f.DBName, true
 		}
-		for j := 0; j < colsF.Len(); j++ {
-			col := colsF.Index(j)
-			if col.Kind() == reflect.Pointer {
-				col = col.Elem()
-			}
-			if col.Kind() != reflect.Struct {
-				return nil, fmt.Errorf("Index %q column %d: not a struct", name, j+1)
-			}
+	}
+	return "", false
+}
 
-			selF := col.FieldByName("Sel")   // func(*T) any
-			sortF := col.FieldByName("Sort") // string
-			nullF := col.FieldByName("Nulls")
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// collectColumnChecks validates the ColumnChecks() of a model, and returns them keyed by field name.
+func collectColumnChecks(sch *schema.Schema, baseStruct reflect.Type, checks map[string]string) (map[string]string, error) {
+	fieldToCheck := make(map[string]string, len(checks))
+	for name, expr := range checks {
+		expr = strings.TrimSpace(expr)
+		sf, ok := baseStruct.FieldByName(name)
+		if !ok || len(sf.Index) != 1 || sf.PkgPath != "" {
+			return nil, fmt.Errorf("check on %q: not a top-level exported field of %s", name, sch.Name)
+		}
+		f := sch.LookUpField(name)
+		if f == nil || f.DBName == "" {
+			return nil, fmt.Errorf("check on %q: field is not mapped to a column", name)
+		}
+		if f.TagSettings["CHECK"] != "" {
+			return nil, fmt.Errorf("check on %q: field already declares a check tag", name)
+		}
+		if strings.Contains(expr, ";") {
+			return nil, fmt.Errorf("check on %q: expression must not contain ';'", name)
+		}
+		if !referencesColumn(expr, f.DBName) {
+			return nil, fmt.Errorf("check on %q: expression %q does not reference column %q", name, expr, f.DBName)
+		}
+		fieldToCheck[name] = expr
+	}
+	return fieldToCheck, nil
+}
+
+// referencesColumn reports whether the given expression references the column, quoted or not.
+// Identifiers within string literals, function names, types and table qualifiers are skipped.
+func referencesColumn(expr, column string) bool {
+	for _, m := range predicateIdent.FindAllStringSubmatch(stringLiteral.ReplaceAllString(expr, "''"), -1) {
+		if strings.EqualFold(cmp.Or(m[2], m[3], m[4], m[5]), column) && !strings.HasPrefix(m[1], "::") && m[6] == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkName matches the constraint names GORM accepts in check tags.
+var checkName = regexp.MustCompile(`^[\w-]+$`)
+
+// tableCheck is a check constraint declared by the Checks() of a model.
+type tableCheck struct {
+	name string
+	expr string
+}
+
+// def returns the definition of the check in CREATE TABLE.
+func (c tableCheck) def(db *gorm.DB) string {
+	return fmt.Sprintf("CONSTRAINT %s CHECK (%s)", db.Statement.Quote(c.name), c.expr)
+}
 
-			if !selF.IsValid() {
-				return nil, fmt.Errorf("Index %q column %d: missing Sel", name, j+1)
+// tableChecks validates the Checks() of a model, and returns them as named constraints. Unnamed
+// checks are named after the first of their columns whose name is not taken by another check,
+// including the ones of ColumnChecks() and check tags.
+func tableChecks(db *gorm.DB, model any) ([]tableCheck, error) {
+	if model == nil || indirectType(reflect.TypeOf(model)).Kind() != reflect.Struct {
+		return nil, nil
+	}
+	checks, ok := checkDefinitions(receiver(model))
+	if !ok {
+		return nil, nil
+	}
+	// Checks might be declared by ColumnChecks(), hence the migrated value is parsed.
+	tx, value, err := migrationTarget(db, model)
+	if err != nil {
+		return nil, err
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.ParseWithSpecialTableName(value, tx.Statement.Table); err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool)
+	for name := range stmt.Schema.ParseCheckConstraints() {
+		taken[name] = true
+	}
+	// Column checks inlined on SQLite keep their names on the other dialects.
+	for _, f := range stmt.Schema.Fields {
+		if f.TagSettings[columnCheck] != "" {
+			taken[tx.NamingStrategy.CheckerName(stmt.Schema.Table, f.DBName)] = true
+		}
+	}
+	var cs []tableCheck
+	for i := 0; i < checks.Len(); i++ {
+		c := checks.Index(i)
+		name := strings.TrimSpace(c.FieldByName("Name").String())
+		label := fmt.Sprintf("%q", name)
+		if name == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+		if name != "" && !checkName.MatchString(name) {
+			return nil, fmt.Errorf("check %s: name must consist of letters, digits, '_' and '-'", label)
+		}
+		expr := strings.TrimSpace(c.FieldByName("Expr").String())
+		if expr == "" {
+			return nil, fmt.Errorf("check %s: empty expression", label)
+		}
+		if strings.Contains(expr, ";") {
+			return nil, fmt.Errorf("check %s: expression must not contain ';'", label)
+		}
+		var columns, names []string
+		cols := c.FieldByName("Cols")
+		for j := 0; j < cols.Len(); j++ {
+			col := cols.Index(j)
+			if col.FieldByName("Expr").String() != "" || col.FieldByName("Sort").String() != "" ||
+				col.FieldByName("Nulls").String() != "" || col.FieldByName("OpClass").String() != "" || col.FieldByName("Collation").String() != "" {
+				return nil, fmt.Errorf("check %s: column %d must be a plain Field", label, j+1)
 			}
-			fname, err := fieldNameFromSelectorValue(selF)
+			fname, err := columnField(col)
 			if err != nil {
-				return nil, fmt.Errorf("index %q column %d: %w", name, j+1, err)
+				return nil, fmt.Errorf("check %s: column %d: %w", label, j+1, err)
 			}
-
-			parts := []string{
-				"index:" + name,
-				fmt.Sprintf("priority:%d", j+1),
+			f := stmt.Schema.LookUpField(fname)
+			if f == nil || f.DBName == "" {
+				return nil, fmt.Errorf("check %s: field %q is not mapped to a column", label, fname)
+			}
+			columns = append(columns, f.DBName)
+			names = append(names, stmt.Quote(f.DBName))
+		}
+		var err error
+		expr = exprPlaceholder.ReplaceAllStringFunc(expr, func(p string) string {
+			n, _ := strconv.Atoi(p[1 : len(p)-1])
+			if n < 1 || n > len(names) {
+				err = fmt.Errorf("check %s: placeholder %s has no column", label, p)
+				return p
 			}
-			if s := strings.TrimSpace(sortF.String()); s != "" {
-				val := s
-				if n := strings.TrimSpace(nullF.String()); n != "" {
-					val = val + " nulls " + n
+			return names[n-1]
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) == 0 {
+			// Raw expressions reference their columns by name.
+			for _, f := range stmt.Schema.Fields {
+				if f.DBName != "" && regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(f.DBName)+`\b`).MatchString(expr) {
+					columns = append(columns, f.DBName)
 				}
-				parts = append(parts, "sort:"+val)
 			}
-			if j == 0 && unique {
-				parts = append(parts, "unique")
+			if len(columns) == 0 {
+				return nil, fmt.Errorf("check %s: expression %q does not reference any column", label, expr)
 			}
-			if j == 0 && where != "" {
-				parts = append(parts, "where:"+where)
+		}
+		if name == "" {
+			for _, column := range columns {
+				if n := tx.NamingStrategy.CheckerName(stmt.Schema.Table, column); !taken[n] {
+					name = n
+					break
+				}
+			}
+			if name == "" {
+				return nil, fmt.Errorf("check %s: the names of its columns are taken by other checks, name it explicitly", label)
 			}
+		} else if taken[name] {
+			return nil, fmt.Errorf("check %s: name is taken by another check", label)
+		}
+		taken[name] = true
+		cs = append(cs, tableCheck{name: name, expr: expr})
+	}
+	return cs, nil
+}
 
-			fieldToIndexTags[fname] = append(fieldToIndexTags[fname], strings.Join(parts, ","))
+// collectGeneratedColumns validates the GeneratedColumns() of a model, that maps a field name to the
+// expression of its stored generated column, and returns the column types of the fields keyed by name.
+func collectGeneratedColumns(stmt *gorm.Statement, baseStruct reflect.Type, columns map[string]string, fieldToIndexTags map[string][]string) (map[string]string, error) {
+	fieldToType := make(map[string]string, len(columns))
+	for name, expr := range columns {
+		expr = strings.TrimSpace(expr)
+		sf, ok := baseStruct.FieldByName(name)
+		if !ok || len(sf.Index) != 1 || sf.PkgPath != "" {
+			return nil, fmt.Errorf("generated column %q: not a top-level exported field of %s", name, stmt.Schema.Name)
 		}
+		f := stmt.Schema.LookUpField(name)
+		switch {
+		case f == nil || f.DBName == "":
+			return nil, fmt.Errorf("generated column %q: field is not mapped to a column", name)
+		case f.PrimaryKey || f.HasDefaultValue:
+			return nil, fmt.Errorf("generated column %q: field cannot be a primary key or have a default", name)
+		case expr == "" || strings.Contains(expr, ";"):
+			return nil, fmt.Errorf("generated column %q: expression must be non-empty and must not contain ';'", name)
+		}
+		if stmt.DB.Dialector.Name() == "sqlserver" {
+			// Computed columns of SQL Server derive their type from the expression.
+			fieldToType[name] = fmt.Sprintf("AS (%s) PERSISTED", expr)
+			continue
+		}
+		typ := f.TagSettings["TYPE"]
+		if typ == "" {
+			// Indexed strings are sized on MySQL.
+			if len(fieldToIndexTags[name]) > 0 {
+				c := *f
+				c.TagSettings = map[string]string{"INDEX": fieldToIndexTags[name][0]}
+				f = &c
+			}
+			typ = dataTypeOf(stmt.DB, f)
+		}
+		fieldToType[name] = fmt.Sprintf("%s GENERATED ALWAYS AS (%s) STORED", typ, expr)
 	}
-	return fieldToIndexTags, nil
+	return fieldToType, nil
+}
+
+// collectDefaults validates the Defaults() of a model, and returns
+// them keyed by field name, with their tokens expanded.
+func collectDefaults(stmt *gorm.Statement, baseStruct reflect.Type, defaults map[string]string) (map[string]string, error) {
+	dialect := stmt.DB.Dialector.Name()
+	fieldToDefault := make(map[string]string, len(defaults))
+	for name, expr := range defaults {
+		expr = strings.TrimSpace(expr)
+		sf, ok := baseStruct.FieldByName(name)
+		if !ok || len(sf.Index) != 1 || sf.PkgPath != "" {
+			return nil, fmt.Errorf("default of %q: not a top-level exported field of %s", name, stmt.Schema.Name)
+		}
+		f := stmt.Schema.LookUpField(name)
+		if f == nil || f.DBName == "" {
+			return nil, fmt.Errorf("default of %q: field is not mapped to a column", name)
+		}
+		if _, ok := f.TagSettings["DEFAULT"]; ok {
+			return nil, fmt.Errorf("default of %q: field already declares a default tag", name)
+		}
+		if strings.Contains(expr, ";") {
+			return nil, fmt.Errorf("default of %q: expression must not contain ';'", name)
+		}
+		if strings.HasPrefix(expr, "@") {
+			var err error
+			if expr, err = defaultToken(dialect, f, expr); err != nil {
+				return nil, fmt.Errorf("default of %q: %w", name, err)
+			}
+		}
+		fieldToDefault[name] = expr
+	}
+	return fieldToDefault, nil
+}
+
+// defaultToken expands the given default token to the expression of the dialect.
+func defaultToken(dialect string, f *schema.Field, token string) (string, error) {
+	switch token {
+	case "@now":
+		switch dialect {
+		case "postgres":
+			return "now()", nil
+		case "mysql":
+			// The precision must match the one of the column, which defaults to 3.
+			p := f.Precision
+			if p == 0 {
+				p = 3
+			}
+			return fmt.Sprintf("CURRENT_TIMESTAMP(%d)", p), nil
+		default:
+			return "CURRENT_TIMESTAMP", nil
+		}
+	case "@true", "@false":
+		if f.DataType != schema.Bool {
+			return "", fmt.Errorf("%s requires a bool field, got %s", token, f.FieldType)
+		}
+		return strings.TrimPrefix(token, "@"), nil
+	case "@uuid":
+		switch dialect {
+		case "postgres":
+			return "gen_random_uuid()", nil
+		case "mysql":
+			return "(UUID())", nil
+		case "sqlserver":
+			return "NEWID()", nil
+		default:
+			// A random version 4 UUID, as SQLite has no builtin function for it.
+			return "(lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6))))", nil
+		}
+	default:
+		return "", fmt.Errorf("unknown default token %q", token)
+	}
+}
+
+// columnField returns the name of the struct field of the given column: the field of its Sel,
+// or its FieldName if Sel is unset, which must be an exported field of the model.
+func columnField(col reflect.Value) (string, error) {
+	sel := col.FieldByName("Sel")
+	if !sel.IsValid() {
+		return "", errors.New("missing Sel")
+	}
+	if !sel.IsNil() {
+		return fieldNameFromSelectorValue(sel)
+	}
+	name := strings.TrimSpace(col.FieldByName("FieldName").String())
+	if name == "" {
+		return "", errors.New("missing Sel or FieldName")
+	}
+	t := sel.Type().In(0).Elem()
+	for _, sf := range flattenFields(t) {
+		if sf.Name == name && sf.PkgPath == "" {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("FieldName %q is not an exported field of %s", name, t.Name())
+}
+
+// columnSelector returns the Sel of the given column, or a selector of its FieldName if Sel
+// is unset. The column is expected to be resolved by columnField.
+func columnSelector(col reflect.Value) reflect.Value {
+	sel := col.FieldByName("Sel")
+	if !sel.IsNil() {
+		return sel
+	}
+	name := strings.TrimSpace(col.FieldByName("FieldName").String())
+	return reflect.MakeFunc(sel.Type(), func(args []reflect.Value) []reflect.Value {
+		return []reflect.Value{args[0].Elem().FieldByName(name).Addr()}
+	})
 }
 
 func fieldNameFromSelectorValue(sel reflect.Value) (string, error) {
@@ -209,27 +3126,80 @@ func fieldNameFromSelectorValue(sel reflect.Value) (string, error) {
 
 	// Make zero *T and call the selector.
 	ptrToT := reflect.New(ft.In(0).Elem()) // *T
+	allocEmbedded(ptrToT.Elem())
 	out := sel.Call([]reflect.Value{ptrToT})
 	if len(out) != 1 {
 		return "", fmt.Errorf("Sel returned unexpected values")
 	}
 
-	// IMPORTANT: unwrap interface{} -> underlying pointer
+	// IMPORTANT: unwrap interface{} -> underlying pointer. Selectors might wrap the field
+	// pointer in further interfaces or pointers (e.g. a pointer to an interface holding it),
+	// hence the chain is followed until it reaches the address of a field of T.
+	v := ptrToT.Elem()
 	res := out[0]
-	if res.Kind() == reflect.Interface {
-		if res.IsNil() {
-			return "", fmt.Errorf("Sel returned a nil interface")
+	for {
+		switch res.Kind() {
+		case reflect.Interface:
+			if res.IsNil() {
+				return "", fmt.Errorf("Sel returned a nil interface")
+			}
+			res = res.Elem()
+		case reflect.Ptr:
+			if res.IsNil() {
+				return "", fmt.Errorf("Sel returned a nil pointer")
+			}
+			if name, ok := fieldAt(v, res); ok {
+				return name, nil
+			}
+			if k := res.Elem().Kind(); k != reflect.Interface && k != reflect.Ptr {
+				if !within(v, res.Pointer()) {
+					// A common mistake is a closure that captures another variable, e.g. &u.Email instead of &m.Email.
+					return "", fmt.Errorf("Sel returned a field of a different value than its *%s argument; the closure must use its own parameter", v.Type().Name())
+				}
+				return "", fmt.Errorf("Sel didn't point to a top-level exported field on %s", v.Type().Name())
+			}
+			res = res.Elem()
+		default:
+			return "", fmt.Errorf("Sel must return a *field (pointer), got %s", res.Type())
 		}
-		res = res.Elem()
 	}
+}
 
-	if res.Kind() != reflect.Ptr || res.IsNil() {
-		return "", fmt.Errorf("Sel must return a *field (pointer)")
+// allocEmbedded allocates the nil pointers of the embedded structs of the given struct,
+// hence selectors can address their promoted fields, e.g. &m.Base.TenantID.
+func allocEmbedded(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		if !flattened(v.Type().Field(i)) {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			fv.Set(reflect.New(fv.Type().Elem()))
+			fv = fv.Elem()
+		}
+		allocEmbedded(fv)
 	}
-	retPtr := res.Pointer()
+}
 
-	// Compare against addresses of top-level exported fields on T
-	v := ptrToT.Elem()
+// within reports whether the given address is part of the memory of the given struct, or of the
+// embedded structs allocated for it (see allocEmbedded).
+func within(v reflect.Value, addr uintptr) bool {
+	if start := v.Addr().Pointer(); addr >= start && addr < start+v.Type().Size() {
+		return true
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if fv := v.Field(i); flattened(v.Type().Field(i)) && fv.Kind() == reflect.Pointer && !fv.IsNil() && within(fv.Elem(), addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldAt returns the name of the exported field of the given struct that the pointer
+// addresses. The type is compared as well, as the first field of a struct shares its
+// address with the struct itself. The fields of embedded structs are resolved to the
+// names GORM promotes them by, unless fields of the outer struct shadow them.
+func fieldAt(v, ptr reflect.Value) (string, bool) {
 	t := v.Type()
 	for i := 0; i < v.NumField(); i++ {
 		sf := t.Field(i)
@@ -237,20 +3207,34 @@ func fieldNameFromSelectorValue(sel reflect.Value) (string, error) {
 			continue
 		}
 		fv := v.Field(i)
-		if fv.CanAddr() && fv.Addr().Pointer() == retPtr {
-			return sf.Name, nil
+		if fv.CanAddr() && fv.Addr().Pointer() == ptr.Pointer() && sf.Type == ptr.Type().Elem() {
+			return sf.Name, true
+		}
+	}
+	for i := 0; i < v.NumField(); i++ {
+		fv := reflect.Indirect(v.Field(i))
+		if !flattened(t.Field(i)) || !fv.IsValid() {
+			continue
+		}
+		if name, ok := fieldAt(fv, ptr); ok {
+			if sf, _ := t.FieldByName(name); len(sf.Index) == 1 {
+				return "", false
+			}
+			return name, true
 		}
 	}
-	return "", fmt.Errorf("Sel didn't point to a top-level exported field on %s", t.Name())
+	return "", false
 }
 
-var tagKV = regexp.MustCompile(`(\w+):"([^"]*)"`)
+var tagKV = regexp.MustCompile(`(\w+):("(?:[^"\\]|\\.)*")`)
 
 func parseStructTag(tag reflect.StructTag) map[string]string {
 	out := map[string]string{}
 	s := string(tag)
 	for _, m := range tagKV.FindAllStringSubmatch(s, -1) {
-		out[m[1]] = m[2]
+		if v, err := strconv.Unquote(m[2]); err == nil {
+			out[m[1]] = v
+		}
 	}
 	return out
 }
@@ -261,12 +3245,23 @@ func buildStructTag(kv map[string]string) reflect.StructTag {
 	}
 	parts := make([]string, 0, len(kv))
 	for k, v := range kv {
-		parts = append(parts, fmt.Sprintf(`%s:"%s"`, k, v))
+		parts = append(parts, k+":"+strconv.Quote(v))
 	}
 	sort.Strings(parts) // deterministic
 	return reflect.StructTag(strings.Join(parts, " "))
 }
 
+// appendGormTag appends the given settings to the gorm tag of the field.
+func appendGormTag(orig reflect.StructTag, settings ...string) reflect.StructTag {
+	kv := parseStructTag(orig)
+	parts := settings
+	if v := kv["gorm"]; v != "" {
+		parts = append([]string{v}, settings...)
+	}
+	kv["gorm"] = strings.Join(parts, ";")
+	return buildStructTag(kv)
+}
+
 // Remove any existing index/uniqueIndex fragments so we don't duplicate them.
 func stripIndexPiecesFromGormTag(gormTag string) string {
 	if gormTag == "" {
@@ -0,0 +1,3212 @@
+package gormschema_test
+
+import (
+	"cmp"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"ariga.io/atlas-provider-gorm/gormschema"
+	"ariga.io/atlas-provider-gorm/internal/testdata/plugin"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+type SoftDeletedUser struct {
+	gorm.Model
+	Email string
+}
+
+func (SoftDeletedUser) Indexes() []gormschema.IndexDefinition[SoftDeletedUser] {
+	return []gormschema.IndexDefinition[SoftDeletedUser]{
+		{
+			Name:    "idx_soft_deleted_users_email",
+			Columns: []gormschema.Col[SoftDeletedUser]{gormschema.Field(func(m *SoftDeletedUser) any { return &m.Email })},
+			Unique:  true,
+			Where:   gormschema.SoftDelete,
+		},
+	}
+}
+
+type HardDeletedUser struct {
+	ID    uint
+	Email string
+}
+
+func (HardDeletedUser) Indexes() []gormschema.IndexDefinition[HardDeletedUser] {
+	return []gormschema.IndexDefinition[HardDeletedUser]{
+		{
+			Name:    "idx_hard_deleted_users_email",
+			Columns: []gormschema.Col[HardDeletedUser]{gormschema.Field(func(m *HardDeletedUser) any { return &m.Email })},
+			Where:   gormschema.SoftDelete,
+		},
+	}
+}
+
+func TestSoftDeleteWhere(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(SoftDeletedUser{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE TABLE "soft_deleted_users"`)
+	require.Contains(t, sql, `CREATE UNIQUE INDEX IF NOT EXISTS "idx_soft_deleted_users_email" ON "soft_deleted_users" ("email") WHERE deleted_at IS NULL;`)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(HardDeletedUser{})
+	require.EqualError(t, err, `index "idx_hard_deleted_users_email": @soft_delete requires a gorm.DeletedAt field on HardDeletedUser`)
+	resetSession()
+}
+
+type Member struct {
+	ID   uint
+	Age  int
+	Name string
+}
+
+func (Member) ColumnChecks() map[string]string {
+	return map[string]string{
+		"Age": "age >= 0",
+	}
+}
+
+type InvalidMember struct {
+	ID  uint
+	Age int
+}
+
+func (InvalidMember) ColumnChecks() map[string]string {
+	return map[string]string{
+		"Age": "id > 0",
+	}
+}
+
+func TestColumnChecks(t *testing.T) {
+	// Checks are created within CREATE TABLE, instead of being added by ALTER TABLE: inline
+	// with their column on SQLite, which requires it, and as table constraints elsewhere.
+	for _, tt := range []struct {
+		dialect string
+		create  string
+	}{
+		{
+			dialect: "postgres",
+			create:  `CREATE TABLE "members" ("id" bigserial NOT NULL,"age" bigint,"name" text,PRIMARY KEY ("id"),CONSTRAINT "chk_members_age" CHECK (age >= 0));`,
+		},
+		{
+			dialect: "mysql",
+			create:  "CREATE TABLE `members` (`id` bigint unsigned AUTO_INCREMENT NOT NULL,`age` bigint,`name` longtext,PRIMARY KEY (`id`),CONSTRAINT `chk_members_age` CHECK (age >= 0));",
+		},
+		{
+			dialect: "sqlite",
+			create:  "CREATE TABLE `members` (`id` integer PRIMARY KEY AUTOINCREMENT NOT NULL,`age` integer CHECK (age >= 0),`name` text);",
+		},
+		{
+			dialect: "sqlserver",
+			create:  `CREATE TABLE "members" ("id" bigint IDENTITY(1,1) NOT NULL,"age" bigint,"name" nvarchar(MAX),PRIMARY KEY ("id"),CONSTRAINT "chk_members_age" CHECK (age >= 0));`,
+		},
+	} {
+		t.Run(tt.dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(tt.dialect).Load(Member{})
+			require.NoError(t, err)
+			require.Equal(t, tt.create+"\n", sql)
+			require.NotContains(t, sql, "ALTER TABLE")
+			resetSession()
+		})
+	}
+	// Unnamed checks are not named after columns that carry a column check on any dialect.
+	for dialect, expected := range map[string]string{
+		"postgres": `CONSTRAINT "chk_gauges_max" CHECK ("low" <= "max")`,
+		"sqlite":   "CONSTRAINT `chk_gauges_max` CHECK (`low` <= `max`)",
+	} {
+		resetSession()
+		sql, err := gormschema.New(dialect).Load(Gauge{})
+		require.NoError(t, err)
+		require.Contains(t, sql, expected, dialect)
+	}
+	resetSession()
+	_, err := gormschema.New("postgres").Load(InvalidMember{})
+	require.EqualError(t, err, `check on "Age": expression "id > 0" does not reference column "age"`)
+	resetSession()
+}
+
+type Gauge struct {
+	ID  uint
+	Low int
+	Max int
+}
+
+func (Gauge) ColumnChecks() map[string]string {
+	return map[string]string{"Low": "low >= 0"}
+}
+
+func (Gauge) Checks() []gormschema.Check[Gauge] {
+	return []gormschema.Check[Gauge]{
+		gormschema.CheckExpr("", "{1} <= {2}",
+			gormschema.Field(func(g *Gauge) any { return &g.Low }),
+			gormschema.Field(func(g *Gauge) any { return &g.Max }),
+		),
+	}
+}
+
+type Booking struct {
+	ID      uint
+	StartAt time.Time
+	EndAt   time.Time
+	Guests  int
+}
+
+func (Booking) Checks() []gormschema.Check[Booking] {
+	return []gormschema.Check[Booking]{
+		gormschema.CheckExpr("chk_bookings_range", "{1} < {2}",
+			gormschema.Field(func(b *Booking) any { return &b.StartAt }),
+			gormschema.Field(func(b *Booking) any { return &b.EndAt }),
+		),
+		// The first column already carries the check above.
+		gormschema.CheckExpr("", "{1} <= {2} + interval '30 days'",
+			gormschema.Field(func(b *Booking) any { return &b.EndAt }),
+			gormschema.Field(func(b *Booking) any { return &b.StartAt }),
+		),
+		{Expr: "guests > 0"},
+	}
+}
+
+type InvalidBooking struct {
+	ID      uint
+	StartAt time.Time
+	EndAt   time.Time
+	checks  []gormschema.Check[InvalidBooking]
+}
+
+func (b InvalidBooking) Checks() []gormschema.Check[InvalidBooking] { return b.checks }
+
+func TestCheckExpr(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Booking{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "bookings" ("id" bigserial NOT NULL,"start_at" timestamptz,"end_at" timestamptz,"guests" bigint,PRIMARY KEY ("id"),`+
+		`CONSTRAINT "chk_bookings_end_at" CHECK ("end_at" <= "start_at" + interval '30 days'),`+
+		`CONSTRAINT "chk_bookings_guests" CHECK (guests > 0),`+
+		`CONSTRAINT "chk_bookings_range" CHECK ("start_at" < "end_at"));`+"\n", sql)
+	resetSession()
+
+	startAt := gormschema.Field(func(b *InvalidBooking) any { return &b.StartAt })
+	endAt := gormschema.Field(func(b *InvalidBooking) any { return &b.EndAt })
+	for _, tt := range []struct {
+		check gormschema.Check[InvalidBooking]
+		err   string
+	}{
+		{gormschema.CheckExpr("chk range", "{1} < {2}", startAt, endAt), `check "chk range": name must consist of letters, digits, '_' and '-'`},
+		{gormschema.CheckExpr("chk_range", "{1} < {3}", startAt, endAt), `check "chk_range": placeholder {3} has no column`},
+		{gormschema.CheckExpr("chk_range", "{1} < now()", gormschema.Desc(startAt)), `check "chk_range": column 1 must be a plain Field`},
+		{gormschema.CheckExpr[InvalidBooking]("", "1 = 1"), `check #1: expression "1 = 1" does not reference any column`},
+	} {
+		_, err := gormschema.New("postgres").Load(InvalidBooking{checks: []gormschema.Check[InvalidBooking]{tt.check}})
+		require.EqualError(t, err, tt.err)
+		resetSession()
+	}
+	// Checks are table constraints, hence columns are not limited to a single check.
+	sql, err = gormschema.New("sqlite").Load(InvalidBooking{checks: []gormschema.Check[InvalidBooking]{
+		gormschema.CheckExpr("chk_a", "{1} < {2}", startAt, endAt),
+		gormschema.CheckExpr("chk_b", "{1} > {2}", endAt, startAt),
+		gormschema.CheckExpr("chk_c", "{1} <> {2}", startAt, endAt),
+	}})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `invalid_bookings` (`id` integer PRIMARY KEY AUTOINCREMENT NOT NULL,`start_at` datetime,`end_at` datetime,"+
+		"CONSTRAINT `chk_a` CHECK (`start_at` < `end_at`),"+
+		"CONSTRAINT `chk_b` CHECK (`end_at` > `start_at`),"+
+		"CONSTRAINT `chk_c` CHECK (`start_at` <> `end_at`));\n", sql)
+	resetSession()
+	// Unnamed checks are named after their first column whose name is not taken.
+	_, err = gormschema.New("postgres").Load(InvalidBooking{checks: []gormschema.Check[InvalidBooking]{
+		gormschema.CheckExpr("", "{1} < {2}", startAt, endAt),
+		gormschema.CheckExpr("", "{1} > {2}", startAt, endAt),
+		gormschema.CheckExpr("", "{1} <> {2}", startAt, endAt),
+	}})
+	require.EqualError(t, err, `check #3: the names of its columns are taken by other checks, name it explicitly`)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(InvalidBooking{checks: []gormschema.Check[InvalidBooking]{
+		gormschema.CheckExpr("chk_a", "{1} < {2}", startAt, endAt),
+		gormschema.CheckExpr("chk_a", "{1} <> {2}", startAt, endAt),
+	}})
+	require.EqualError(t, err, `check "chk_a": name is taken by another check`)
+	resetSession()
+}
+
+type Ticket struct {
+	gorm.Model
+	Title    string
+	Status   string
+	Priority int
+	Assignee string `gorm:"index"`
+}
+
+func (Ticket) Indexes() []gormschema.IndexDefinition[Ticket] {
+	return []gormschema.IndexDefinition[Ticket]{
+		{Name: "idx_tickets_title", Columns: []gormschema.Col[Ticket]{gormschema.Field(func(m *Ticket) any { return &m.Title })}},
+		{Name: "idx_tickets_status", Columns: []gormschema.Col[Ticket]{gormschema.Field(func(m *Ticket) any { return &m.Status })}},
+		{Name: "idx_tickets_priority", Columns: []gormschema.Col[Ticket]{gormschema.Field(func(m *Ticket) any { return &m.Priority })}},
+	}
+}
+
+func TestIndexesOrder(t *testing.T) {
+	for dialect, names := range map[string][]string{
+		"postgres": {`"idx_tickets_deleted_at"`, `"idx_tickets_priority"`, `"idx_tickets_status"`, `"idx_tickets_title"`},
+		"mysql":    {"`idx_tickets_deleted_at`", "`idx_tickets_priority`", "`idx_tickets_status`", "`idx_tickets_title`"},
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			expected, err := gormschema.New(dialect).Load(Ticket{})
+			require.NoError(t, err)
+			for i := 1; i < len(names); i++ {
+				require.Less(t, strings.Index(expected, names[i-1]), strings.Index(expected, names[i]))
+			}
+			for range 20 {
+				resetSession()
+				sql, err := gormschema.New(dialect).Load(Ticket{})
+				require.NoError(t, err)
+				require.Equal(t, expected, sql)
+			}
+			resetSession()
+		})
+	}
+}
+
+type Subscription struct {
+	ID     uint
+	Email  string
+	Plan   string
+	Active bool
+}
+
+func (Subscription) Indexes() []gormschema.IndexDefinition[Subscription] {
+	return []gormschema.IndexDefinition[Subscription]{
+		{
+			Name:    "idx_subscriptions_email",
+			Columns: []gormschema.Col[Subscription]{gormschema.Field(func(m *Subscription) any { return &m.Email })},
+			Conds: []gormschema.Cond[Subscription]{
+				gormschema.WhereEq(func(m *Subscription) any { return &m.Plan }, "pro, o'neil; vip"),
+				gormschema.WhereEq(func(m *Subscription) any { return &m.Active }, true),
+			},
+		},
+	}
+}
+
+func TestWhereEq(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres":  `CREATE INDEX IF NOT EXISTS "idx_subscriptions_email" ON "subscriptions" ("email") WHERE "plan" = 'pro, o''neil; vip' AND "active" = true;`,
+		"sqlite":    "CREATE INDEX `idx_subscriptions_email` ON `subscriptions`(`email`) WHERE `plan` = 'pro, o''neil; vip' AND `active` = 1;",
+		"sqlserver": `CREATE INDEX "idx_subscriptions_email" ON "subscriptions"("email") WHERE "plan" = 'pro, o''neil; vip' AND "active" = 1;`,
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Subscription{})
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+			resetSession()
+		})
+	}
+	indexes, err := gormschema.New("postgres").DescribeIndexes(Subscription{})
+	require.NoError(t, err)
+	require.Equal(t, `"plan" = 'pro, o''neil; vip' AND "active" = true`, indexes[0].Where)
+}
+
+type Account struct {
+	ID        string
+	Active    bool
+	Archived  bool
+	CreatedAt time.Time
+}
+
+func (Account) Defaults() map[string]string {
+	return map[string]string{
+		"ID":        "@uuid",
+		"Active":    "@true",
+		"Archived":  "@false",
+		"CreatedAt": "@now",
+	}
+}
+
+type InvalidAccount struct {
+	ID   uint
+	Name string
+}
+
+func (InvalidAccount) Defaults() map[string]string {
+	return map[string]string{
+		"Name": "@true",
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres":  `CREATE TABLE "accounts" ("id" text NOT NULL DEFAULT gen_random_uuid(),"active" boolean DEFAULT true,"archived" boolean DEFAULT false,"created_at" timestamptz DEFAULT now(),PRIMARY KEY ("id"));`,
+		"mysql":     "CREATE TABLE `accounts` (`id` varchar(191) NOT NULL DEFAULT (UUID()),`active` boolean DEFAULT true,`archived` boolean DEFAULT false,`created_at` datetime(3) NULL DEFAULT CURRENT_TIMESTAMP(3),PRIMARY KEY (`id`));",
+		"sqlite":    "CREATE TABLE `accounts` (`id` text NOT NULL DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', abs(random()) % 4 + 1, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))),`active` numeric DEFAULT true,`archived` numeric DEFAULT false,`created_at` datetime DEFAULT CURRENT_TIMESTAMP,PRIMARY KEY (`id`));",
+		"sqlserver": `CREATE TABLE "accounts" ("id" nvarchar(256) NOT NULL DEFAULT NEWID(),"active" bit DEFAULT 1,"archived" bit DEFAULT 0,"created_at" datetimeoffset DEFAULT CURRENT_TIMESTAMP,PRIMARY KEY ("id"));`,
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Account{})
+			require.NoError(t, err)
+			require.Equal(t, expected+"\n", sql)
+			resetSession()
+		})
+	}
+	resetSession()
+	_, err := gormschema.New("postgres").Load(InvalidAccount{})
+	require.EqualError(t, err, `default of "Name": @true requires a bool field, got string`)
+	resetSession()
+}
+
+type Document struct {
+	ID     uint
+	Title  string
+	Tags   []string `gorm:"type:text[]"`
+	Attrs  string   `gorm:"type:jsonb"`
+	Search string   `gorm:"type:tsvector"`
+}
+
+func (Document) Indexes() []gormschema.IndexDefinition[Document] {
+	return []gormschema.IndexDefinition[Document]{
+		{Name: "idx_documents_tags", Type: "gin", Columns: []gormschema.Col[Document]{gormschema.Field(func(m *Document) any { return &m.Tags })}},
+		{Name: "idx_documents_attrs", Type: "gin", Columns: []gormschema.Col[Document]{gormschema.Field(func(m *Document) any { return &m.Attrs })}},
+		{Name: "idx_documents_search", Type: "gin", Columns: []gormschema.Col[Document]{gormschema.Field(func(m *Document) any { return &m.Search })}},
+		{Name: "idx_documents_title", Type: "gin", Columns: []gormschema.Col[Document]{gormschema.Class(gormschema.Field(func(m *Document) any { return &m.Title }), "gin_trgm_ops")}},
+	}
+}
+
+type Counter struct {
+	ID    uint
+	Views int
+}
+
+func (Counter) Indexes() []gormschema.IndexDefinition[Counter] {
+	return []gormschema.IndexDefinition[Counter]{
+		{Name: "idx_counters_views", Type: "gin", Columns: []gormschema.Col[Counter]{gormschema.Field(func(m *Counter) any { return &m.Views })}},
+	}
+}
+
+func TestGinColumnTypes(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Document{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_documents_attrs" ON "documents" USING gin("attrs");`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_documents_search" ON "documents" USING gin("search");`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_documents_tags" ON "documents" USING gin("tags");`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_documents_title" ON "documents" USING gin("title" gin_trgm_ops);`)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(Counter{})
+	require.EqualError(t, err, `index "idx_counters_views": column "views" of type bigint has no default operator class for gin, use an array, jsonb or tsvector column, or an operator class such as gin_trgm_ops`)
+	resetSession()
+}
+
+type InlineSeat struct {
+	ID     uint
+	Row    string
+	Number int
+}
+
+func (InlineSeat) TableName() string { return "seats" }
+
+func (InlineSeat) Indexes() []gormschema.IndexDefinition[InlineSeat] {
+	return []gormschema.IndexDefinition[InlineSeat]{
+		{
+			Name: "uq_seats_position",
+			Columns: []gormschema.Col[InlineSeat]{
+				gormschema.Field(func(m *InlineSeat) any { return &m.Row }),
+				gormschema.Field(func(m *InlineSeat) any { return &m.Number }),
+			},
+			Unique: true,
+			Style:  gormschema.InlineConstraint,
+		},
+	}
+}
+
+type AlterSeat struct {
+	ID     uint
+	Row    string
+	Number int
+}
+
+func (AlterSeat) TableName() string { return "seats" }
+
+func (AlterSeat) Indexes() []gormschema.IndexDefinition[AlterSeat] {
+	return []gormschema.IndexDefinition[AlterSeat]{
+		{
+			Name: "uq_seats_position",
+			Columns: []gormschema.Col[AlterSeat]{
+				gormschema.Field(func(m *AlterSeat) any { return &m.Row }),
+				gormschema.Field(func(m *AlterSeat) any { return &m.Number }),
+			},
+			Unique: true,
+			Style:  gormschema.AlterConstraint,
+		},
+	}
+}
+
+func TestUniqueConstraintStyle(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(InlineSeat{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "seats" ("id" bigserial NOT NULL,"row" text,"number" bigint,PRIMARY KEY ("id"),CONSTRAINT "uq_seats_position" UNIQUE ("row","number"));`+"\n", sql)
+	resetSession()
+	sql, err = gormschema.New("postgres").Load(AlterSeat{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "seats" ("id" bigserial NOT NULL,"row" text,"number" bigint,PRIMARY KEY ("id"));`+"\n"+
+		`ALTER TABLE "seats" ADD CONSTRAINT "uq_seats_position" UNIQUE ("row","number");`+"\n", sql)
+	resetSession()
+	sql, err = gormschema.New("sqlite").Load(InlineSeat{})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `seats` (`id` integer PRIMARY KEY AUTOINCREMENT NOT NULL,`row` text,`number` integer,CONSTRAINT `uq_seats_position` UNIQUE (`row`,`number`));\n", sql)
+	resetSession()
+	_, err = gormschema.New("sqlite").Load(AlterSeat{})
+	require.EqualError(t, err, `constraint "uq_seats_position": sqlite supports only inline unique constraints`)
+	// String columns of constraints are sized as keys.
+	resetSession()
+	sql, err = gormschema.New("mysql").Load(InlineSeat{})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `seats` (`id` bigint unsigned AUTO_INCREMENT NOT NULL,`row` varchar(191),`number` bigint,PRIMARY KEY (`id`),CONSTRAINT `uq_seats_position` UNIQUE (`row`,`number`));\n", sql)
+	resetSession()
+	sql, err = gormschema.New("mysql").Load(AlterSeat{})
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `seats` (`id` bigint unsigned AUTO_INCREMENT NOT NULL,`row` varchar(191),`number` bigint,PRIMARY KEY (`id`));\n"+
+		"ALTER TABLE `seats` ADD CONSTRAINT `uq_seats_position` UNIQUE (`row`,`number`);\n", sql)
+	resetSession()
+	sql, err = gormschema.New("sqlserver").Load(AlterSeat{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "seats" ("id" bigint IDENTITY(1,1) NOT NULL,"row" nvarchar(256),"number" bigint,PRIMARY KEY ("id"));`+"\n"+
+		`ALTER TABLE "seats" ADD CONSTRAINT "uq_seats_position" UNIQUE ("row","number");`+"\n", sql)
+	resetSession()
+	sql, err = gormschema.New("mysql", gormschema.WithCanonicalTypes()).Load(InlineSeat{})
+	require.NoError(t, err)
+	require.Contains(t, sql, "`row` varchar(191)")
+	resetSession()
+}
+
+type Contact struct {
+	ID    uint
+	Name  string
+	Email string
+	Phone string
+}
+
+func (Contact) Indexes() []gormschema.IndexDefinition[Contact] {
+	return []gormschema.IndexDefinition[Contact]{
+		{
+			Name: "idx_contacts_name_email",
+			Columns: []gormschema.Col[Contact]{
+				gormschema.Class(gormschema.NullsLast(gormschema.Asc(gormschema.Field(func(m *Contact) any { return &m.Name }))), "text_pattern_ops"),
+				gormschema.NullsFirst(gormschema.Desc(gormschema.Field(func(m *Contact) any { return &m.Email }))),
+			},
+		},
+		{
+			Name:    "idx_contacts_phone",
+			Columns: []gormschema.Col[Contact]{gormschema.Class(gormschema.NullsFirst(gormschema.Field(func(m *Contact) any { return &m.Phone })), "text_pattern_ops")},
+		},
+	}
+}
+
+func TestColModifiers(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Contact{})
+	require.NoError(t, err)
+	requireEqualContent(t, sql, "testdata/postgresql_index_modifiers.sql")
+	resetSession()
+}
+
+func TestUnexportedModels(t *testing.T) {
+	var models []any
+	for _, m := range plugin.Models() {
+		models = append(models, m)
+	}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(models...)
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "plugin_widgets" ("id" bigserial NOT NULL,"tenant_id" bigint,"slug" text,PRIMARY KEY ("id"));`+"\n"+
+		`CREATE UNIQUE INDEX IF NOT EXISTS "idx_plugin_widgets_tenant_slug" ON "plugin_widgets" ("tenant_id","slug");`+"\n", sql)
+	resetSession()
+}
+
+type Coupon struct {
+	ID       uint
+	Code     string
+	IsActive bool
+}
+
+func (Coupon) Indexes() []gormschema.IndexDefinition[Coupon] {
+	return []gormschema.IndexDefinition[Coupon]{
+		{
+			Name:    "idx_coupons_code",
+			Columns: []gormschema.Col[Coupon]{gormschema.Field(func(m *Coupon) any { return &m.Code })},
+			Unique:  true,
+			Where:   "is_active",
+		},
+	}
+}
+
+func TestBoolWhere(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres":  `CREATE UNIQUE INDEX IF NOT EXISTS "idx_coupons_code" ON "coupons" ("code") WHERE is_active;`,
+		"sqlite":    "CREATE UNIQUE INDEX `idx_coupons_code` ON `coupons`(`code`) WHERE is_active = 1;",
+		"sqlserver": `CREATE UNIQUE INDEX "idx_coupons_code" ON "coupons"("code") WHERE is_active = 1;`,
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Coupon{})
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+			resetSession()
+		})
+	}
+}
+
+type Product struct {
+	ID    uint
+	Price int
+}
+
+func (Product) ColumnChecks() map[string]string {
+	return map[string]string{
+		"Price": "price > 0",
+	}
+}
+
+func (Product) Checks() []gormschema.Check[Product] {
+	return []gormschema.Check[Product]{
+		{Name: "chk_products_price_cap", Expr: "price <= 1000000"},
+	}
+}
+
+func (Product) ConstraintComments() map[string]string {
+	return map[string]string{
+		"chk_products_price":     "Prices can't be free",
+		"chk_products_price_cap": "Prices are capped",
+	}
+}
+
+type InvalidProduct struct {
+	ID    uint
+	Price int
+}
+
+func (InvalidProduct) ConstraintComments() map[string]string {
+	return map[string]string{
+		"chk_invalid_products_price": "Prices can't be free",
+	}
+}
+
+func TestConstraintComments(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Product{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "products" ("id" bigserial NOT NULL,"price" bigint,PRIMARY KEY ("id"),`+
+		`CONSTRAINT "chk_products_price" CHECK (price > 0),CONSTRAINT "chk_products_price_cap" CHECK (price <= 1000000));`+"\n"+
+		`COMMENT ON CONSTRAINT "chk_products_price" ON "products" IS 'Prices can''t be free';`+"\n"+
+		`COMMENT ON CONSTRAINT "chk_products_price_cap" ON "products" IS 'Prices are capped';`+"\n", sql)
+	resetSession()
+	sql, err = gormschema.New("mysql").Load(Product{})
+	require.NoError(t, err)
+	require.NotContains(t, sql, "COMMENT")
+	resetSession()
+	_, err = gormschema.New("postgres").Load(InvalidProduct{})
+	require.EqualError(t, err, `comment on "chk_invalid_products_price": no such constraint on InvalidProduct`)
+	resetSession()
+}
+
+type Subscriber struct {
+	ID         uint
+	Email      string
+	EmailLower string
+}
+
+func (Subscriber) GeneratedColumns() map[string]string {
+	return map[string]string{
+		"EmailLower": "lower(email)",
+	}
+}
+
+func (Subscriber) Indexes() []gormschema.IndexDefinition[Subscriber] {
+	return []gormschema.IndexDefinition[Subscriber]{
+		{
+			Name:    "idx_subscribers_email_lower",
+			Columns: []gormschema.Col[Subscriber]{gormschema.Field(func(m *Subscriber) any { return &m.EmailLower })},
+			Unique:  true,
+		},
+	}
+}
+
+func TestGeneratedColumns(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres": `CREATE TABLE "subscribers" ("id" bigserial NOT NULL,"email" text,"email_lower" text GENERATED ALWAYS AS (lower(email)) STORED,PRIMARY KEY ("id"));` + "\n" +
+			`CREATE UNIQUE INDEX IF NOT EXISTS "idx_subscribers_email_lower" ON "subscribers" ("email_lower");` + "\n",
+		"mysql": "CREATE TABLE `subscribers` (`id` bigint unsigned AUTO_INCREMENT NOT NULL,`email` longtext,`email_lower` varchar(191) GENERATED ALWAYS AS (lower(email)) STORED,PRIMARY KEY (`id`),UNIQUE INDEX `idx_subscribers_email_lower` (`email_lower`));\n",
+		"sqlite": "CREATE TABLE `subscribers` (`id` integer PRIMARY KEY AUTOINCREMENT NOT NULL,`email` text,`email_lower` text GENERATED ALWAYS AS (lower(email)) STORED);\n" +
+			"CREATE UNIQUE INDEX `idx_subscribers_email_lower` ON `subscribers`(`email_lower`);\n",
+		"sqlserver": `CREATE TABLE "subscribers" ("id" bigint IDENTITY(1,1) NOT NULL,"email" nvarchar(MAX),"email_lower" AS (lower(email)) PERSISTED,PRIMARY KEY ("id"));` + "\n" +
+			`CREATE UNIQUE INDEX "idx_subscribers_email_lower" ON "subscribers"("email_lower");` + "\n",
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Subscriber{})
+			require.NoError(t, err)
+			require.Equal(t, expected, sql)
+			resetSession()
+		})
+	}
+}
+
+type Shape struct {
+	ID   uint
+	Area string `gorm:"type:app.area"`
+}
+
+func (Shape) Indexes() []gormschema.IndexDefinition[Shape] {
+	return []gormschema.IndexDefinition[Shape]{
+		{
+			Name:    "idx_shapes_area",
+			Columns: []gormschema.Col[Shape]{gormschema.Class(gormschema.Field(func(m *Shape) any { return &m.Area }), "app.area_ops")},
+		},
+	}
+}
+
+type InvalidShape struct {
+	ID   uint
+	Area string
+}
+
+func (InvalidShape) Indexes() []gormschema.IndexDefinition[InvalidShape] {
+	return []gormschema.IndexDefinition[InvalidShape]{
+		{
+			Name:    "idx_invalid_shapes_area",
+			Columns: []gormschema.Col[InvalidShape]{gormschema.Class(gormschema.Field(func(m *InvalidShape) any { return &m.Area }), "area_ops) WHERE (true")},
+		},
+	}
+}
+
+func TestQualifiedOpClass(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Shape{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_shapes_area" ON "shapes" ("area" app.area_ops);`)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(InvalidShape{})
+	require.EqualError(t, err, `index "idx_invalid_shapes_area" column 1: invalid operator class "area_ops) WHERE (true"`)
+	resetSession()
+}
+
+type Article struct {
+	ID      uint
+	Title   string
+	Body    string
+	indexes []gormschema.IndexDefinition[Article]
+}
+
+func (a Article) Indexes() []gormschema.IndexDefinition[Article] {
+	return a.indexes
+}
+
+func TestIndexTypeConflicts(t *testing.T) {
+	title := gormschema.Field(func(m *Article) any { return &m.Title })
+	body := gormschema.Field(func(m *Article) any { return &m.Body })
+	for _, tt := range []struct {
+		name string
+		defs []gormschema.IndexDefinition[Article]
+		want string
+	}{
+		{
+			name: "type",
+			defs: []gormschema.IndexDefinition[Article]{
+				{Name: "idx_articles_title", Type: "btree", Columns: []gormschema.Col[Article]{gormschema.Class(title, "gin_trgm_ops")}},
+			},
+			want: `index "idx_articles_title": operator class "gin_trgm_ops" implies access method "gin", but Type is "btree"`,
+		},
+		{
+			name: "opclasses",
+			defs: []gormschema.IndexDefinition[Article]{
+				{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{gormschema.Class(title, "gin_trgm_ops"), gormschema.Class(body, "gist_trgm_ops")}},
+			},
+			want: `index "idx_articles_title": operator classes "gin_trgm_ops" and "gist_trgm_ops" imply different access methods (gin and gist)`,
+		},
+		{
+			name: "duplicate",
+			defs: []gormschema.IndexDefinition[Article]{
+				{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{title}},
+				{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{body}},
+			},
+			want: `index "idx_articles_title" declared twice`,
+		},
+		{
+			name: "definitions",
+			defs: []gormschema.IndexDefinition[Article]{
+				{Name: "idx_articles_title", Type: "gin", Columns: []gormschema.Col[Article]{gormschema.Class(title, "gin_trgm_ops")}},
+				{Name: "idx_articles_title", Type: "gist", Columns: []gormschema.Col[Article]{gormschema.Class(body, "gist_trgm_ops")}},
+			},
+			want: `index "idx_articles_title" declared twice`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSession()
+			_, err := gormschema.New("postgres").Load(Article{indexes: tt.defs})
+			require.EqualError(t, err, tt.want)
+			resetSession()
+		})
+	}
+	// The access method is inferred from the operator class.
+	article := Article{indexes: []gormschema.IndexDefinition[Article]{
+		{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{gormschema.Class(title, "gin_trgm_ops")}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(article)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_articles_title" ON "articles" USING gin("title" gin_trgm_ops);`)
+	// Unnamed indexes are named by GORM, hence they are never declared twice.
+	resetSession()
+	sql, err = gormschema.New("postgres").Load(Article{indexes: []gormschema.IndexDefinition[Article]{
+		{Columns: []gormschema.Col[Article]{title}},
+		{Columns: []gormschema.Col[Article]{body}},
+	}})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_articles_body" ON "articles" ("body");
+CREATE INDEX IF NOT EXISTS "idx_articles_title" ON "articles" ("title");
+`)
+	resetSession()
+}
+
+type Session struct {
+	ID        uint
+	Token     string
+	ExpiresAt time.Time
+}
+
+func (Session) Indexes() []gormschema.IndexDefinition[Session] {
+	return []gormschema.IndexDefinition[Session]{
+		{
+			Name:      "idx_sessions_expires_at",
+			Columns:   []gormschema.Col[Session]{gormschema.Field(func(m *Session) any { return &m.ExpiresAt })},
+			Invisible: true,
+		},
+	}
+}
+
+func TestInvisibleIndexes(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"mysql":   "INDEX `idx_sessions_expires_at` (`expires_at`) INVISIBLE)",
+		"mariadb": "INDEX `idx_sessions_expires_at` (`expires_at`) IGNORED)",
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Session{})
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+			resetSession()
+		})
+	}
+	resetSession()
+	_, err := gormschema.New("postgres").Load(Session{})
+	require.EqualError(t, err, `index "idx_sessions_expires_at": invisible indexes are supported only by MySQL and MariaDB`)
+	resetSession()
+}
+
+type Invoice struct {
+	ID       uint
+	Number   string
+	Total    int
+	Rendered string
+}
+
+func (Invoice) IgnoredColumns() []string {
+	return []string{"Rendered"}
+}
+
+type InvalidInvoice struct {
+	ID     uint
+	Number string
+}
+
+func (InvalidInvoice) IgnoredColumns() []string {
+	return []string{"Rendered"}
+}
+
+type IndexedInvoice struct {
+	ID       uint
+	Number   string
+	Rendered string
+}
+
+func (IndexedInvoice) IgnoredColumns() []string {
+	return []string{"Rendered"}
+}
+
+func (IndexedInvoice) Indexes() []gormschema.IndexDefinition[IndexedInvoice] {
+	return []gormschema.IndexDefinition[IndexedInvoice]{
+		{
+			Name: "idx_indexed_invoices_number_rendered",
+			Columns: []gormschema.Col[IndexedInvoice]{
+				gormschema.Field(func(m *IndexedInvoice) any { return &m.Number }),
+				gormschema.Field(func(m *IndexedInvoice) any { return &m.Rendered }),
+			},
+		},
+	}
+}
+
+func TestIgnoredColumns(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Invoice{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "invoices" ("id" bigserial NOT NULL,"number" text,"total" bigint,PRIMARY KEY ("id"));`+"\n", sql)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(InvalidInvoice{})
+	require.EqualError(t, err, `ignored column "Rendered": not a top-level exported field of InvalidInvoice`)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(IndexedInvoice{})
+	require.EqualError(t, err, `index "idx_indexed_invoices_number_rendered": field "Rendered" is excluded from the migrated model IndexedInvoice`)
+	resetSession()
+}
+
+type Order struct {
+	ID         uint
+	CustomerID uint
+	Status     string
+	Total      int
+	indexes    []gormschema.IndexDefinition[Order]
+}
+
+func (o Order) Indexes() []gormschema.IndexDefinition[Order] {
+	return o.indexes
+}
+
+func TestIncludeColumns(t *testing.T) {
+	customer := gormschema.Field(func(m *Order) any { return &m.CustomerID })
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	total := gormschema.Field(func(m *Order) any { return &m.Total })
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Include: []gormschema.Col[Order]{status, total}, Where: "total > 0"},
+	}}
+	for dialect, expected := range map[string]string{
+		"postgres":  `CREATE INDEX IF NOT EXISTS "idx_orders_customer" ON "orders" ("customer_id") INCLUDE ("status","total") WHERE total > 0;`,
+		"sqlserver": `CREATE INDEX "idx_orders_customer" ON "orders"("customer_id") INCLUDE ("status","total") WHERE total > 0;`,
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(order)
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+			resetSession()
+		})
+	}
+	for _, tt := range []struct {
+		name string
+		defs []gormschema.IndexDefinition[Order]
+		want string
+	}{
+		{
+			name: "duplicate",
+			defs: []gormschema.IndexDefinition[Order]{
+				{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer, status}, Include: []gormschema.Col[Order]{status}},
+			},
+			want: `index "idx_orders_customer": column "status" is both a key and an included column`,
+		},
+		{
+			name: "sorted",
+			defs: []gormschema.IndexDefinition[Order]{
+				{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Include: []gormschema.Col[Order]{gormschema.Desc(total)}},
+			},
+			want: `index "idx_orders_customer" included column 1: included columns cannot have sort, nulls or opclass`,
+		},
+		{
+			name: "opclass",
+			defs: []gormschema.IndexDefinition[Order]{
+				{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Include: []gormschema.Col[Order]{gormschema.Class(status, "text_pattern_ops")}},
+			},
+			want: `index "idx_orders_customer" included column 1: included columns cannot have sort, nulls or opclass`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSession()
+			_, err := gormschema.New("postgres").Load(Order{indexes: tt.defs})
+			require.EqualError(t, err, tt.want)
+			resetSession()
+		})
+	}
+}
+
+type Consignment struct {
+	ID       uint
+	Carrier  string `gorm:"size:64"`
+	Tracking string `gorm:"size:64"`
+}
+
+func (Consignment) Indexes() []gormschema.IndexDefinition[Consignment] {
+	return []gormschema.IndexDefinition[Consignment]{
+		{Name: "idx_{table}_carrier", Columns: []gormschema.Col[Consignment]{gormschema.Field(func(m *Consignment) any { return &m.Carrier })}, Disabled: true},
+		{Name: "idx_consignments_tracking", Columns: []gormschema.Col[Consignment]{gormschema.Field(func(m *Consignment) any { return &m.Tracking })}},
+		{Name: "uq_consignments_tracking", Columns: []gormschema.Col[Consignment]{gormschema.Field(func(m *Consignment) any { return &m.Tracking })}, Unique: true, Style: gormschema.AlterConstraint, Disabled: true},
+	}
+}
+
+func TestDisabledIndexes(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("sqlserver").Load(Consignment{})
+	require.NoError(t, err)
+	// Indexes are disabled after the UNIQUE constraints are added.
+	require.Contains(t, sql, `CREATE INDEX "idx_consignments_carrier" ON "consignments"("carrier");
+CREATE INDEX "idx_consignments_tracking" ON "consignments"("tracking");
+ALTER TABLE "consignments" ADD CONSTRAINT "uq_consignments_tracking" UNIQUE ("tracking");
+ALTER INDEX "idx_consignments_carrier" ON "consignments" DISABLE;
+ALTER INDEX "uq_consignments_tracking" ON "consignments" DISABLE;
+`)
+	require.Equal(t, 2, strings.Count(sql, "DISABLE"))
+	// Other dialects ignore them with a warning.
+	for _, dialect := range []string{"postgres", "mysql"} {
+		l := &warnLogger{Interface: logger.Discard}
+		resetSession()
+		sql, err := gormschema.New(dialect, gormschema.WithLogger(l)).Load(Consignment{})
+		require.NoError(t, err)
+		require.NotContains(t, sql, "DISABLE")
+		require.Equal(t, []string{
+			`index "idx_consignments_carrier": disabled indexes are supported only by SQL Server and were ignored`,
+			`index "uq_consignments_tracking": disabled indexes are supported only by SQL Server and were ignored`,
+		}, l.warns)
+	}
+	resetSession()
+}
+
+func TestWithDroppedIncludes(t *testing.T) {
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{
+			Name:    "idx_orders_customer",
+			Columns: []gormschema.Col[Order]{gormschema.Field(func(m *Order) any { return &m.CustomerID })},
+			Include: []gormschema.Col[Order]{gormschema.Field(func(m *Order) any { return &m.Status })},
+		},
+	}}
+	for dialect, expected := range map[string]string{
+		"sqlite": "CREATE INDEX `idx_orders_customer` ON `orders`(`customer_id`);",
+		"mysql":  "INDEX `idx_orders_customer` (`customer_id`)",
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			_, err := gormschema.New(dialect).Load(order)
+			require.EqualError(t, err, `index "idx_orders_customer": included columns are supported only by PostgreSQL and SQL Server`)
+			resetSession()
+			l := &warnLogger{Interface: logger.Discard}
+			sql, err := gormschema.New(dialect, gormschema.WithLogger(l), gormschema.WithDroppedIncludes()).Load(order)
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+			require.NotContains(t, sql, "INCLUDE")
+			require.Contains(t, l.warns, `index "idx_orders_customer": included columns are not supported by `+dialect+` and were dropped`)
+			resetSession()
+		})
+	}
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithDroppedIncludes()).Load(order)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_customer" ON "orders" ("customer_id") INCLUDE ("status");`)
+	resetSession()
+}
+
+func TestConcurrentIndex(t *testing.T) {
+	customer := gormschema.Field(func(m *Order) any { return &m.CustomerID })
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Concurrent: true},
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Unique: true, Concurrent: true, Where: "status <> ''"},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(order)
+	require.NoError(t, err)
+	require.Equal(t, `-- atlas:txmode none
+
+CREATE TABLE "orders" ("id" bigserial NOT NULL,"customer_id" bigint,"status" text,"total" bigint,PRIMARY KEY ("id"));
+CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_orders_customer" ON "orders" ("customer_id");
+CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS "idx_orders_status" ON "orders" ("status") WHERE status <> '';
+`, sql)
+
+	// MySQL does not support partial indexes.
+	order.indexes[1].Where = ""
+	resetSession()
+	l := &warnLogger{Interface: logger.Discard}
+	sql, err = gormschema.New("mysql", gormschema.WithLogger(l)).Load(order)
+	require.NoError(t, err)
+	require.NotContains(t, sql, "CONCURRENTLY")
+	require.NotContains(t, sql, "atlas:txmode")
+	require.Equal(t, []string{
+		`index "idx_orders_customer": concurrent builds are supported only by PostgreSQL and were ignored`,
+		`index "idx_orders_status": concurrent builds are supported only by PostgreSQL and were ignored`,
+	}, l.warns)
+
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "uq_orders_status", Columns: []gormschema.Col[Order]{status}, Unique: true, Style: gormschema.AlterConstraint, Concurrent: true},
+	}
+	resetSession()
+	_, err = gormschema.New("postgres").Load(order)
+	require.EqualError(t, err, `index "uq_orders_status": constraints cannot be built concurrently`)
+	resetSession()
+}
+
+func TestConcurrentIndexDown(t *testing.T) {
+	customer := gormschema.Field(func(m *Order) any { return &m.CustomerID })
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Concurrent: true},
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").LoadDown(order)
+	require.NoError(t, err)
+	require.Equal(t, `-- atlas:txmode none
+
+DROP INDEX IF EXISTS "idx_orders_status";
+DROP INDEX CONCURRENTLY IF EXISTS "idx_orders_customer";
+DROP TABLE IF EXISTS "orders";
+`, sql)
+	// Other dialects build and drop the indexes as usual.
+	resetSession()
+	sql, err = gormschema.New("sqlite", gormschema.WithLogger(logger.Discard)).LoadDown(order)
+	require.NoError(t, err)
+	require.Equal(t, "DROP INDEX IF EXISTS `idx_orders_status`;\nDROP INDEX IF EXISTS `idx_orders_customer`;\nDROP TABLE IF EXISTS `orders`;\n", sql)
+	resetSession()
+}
+
+func TestReplaceIndex(t *testing.T) {
+	customer := gormschema.Field(func(m *Order) any { return &m.CustomerID })
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	total := gormschema.Field(func(m *Order) any { return &m.Total })
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_total", Columns: []gormschema.Col[Order]{total}, Replace: true},
+		{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Replace: true, Concurrent: true},
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(order)
+	require.NoError(t, err)
+	require.Equal(t, `-- atlas:txmode none
+
+CREATE TABLE "orders" ("id" bigserial NOT NULL,"customer_id" bigint,"status" text,"total" bigint,PRIMARY KEY ("id"));
+DROP INDEX CONCURRENTLY IF EXISTS "idx_orders_customer";
+CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_orders_customer" ON "orders" ("customer_id");
+CREATE INDEX IF NOT EXISTS "idx_orders_status" ON "orders" ("status");
+DROP INDEX IF EXISTS "idx_orders_total";
+CREATE INDEX IF NOT EXISTS "idx_orders_total" ON "orders" ("total");
+`, sql)
+
+	// Recorders might return a copy of their statements.
+	copied, err := gormschema.New("postgres", gormschema.WithRecorder(&copyRecorder{})).Load(order)
+	require.NoError(t, err)
+	require.Equal(t, sql, copied)
+
+	resetSession()
+	sql, err = gormschema.New("sqlite", gormschema.WithLogger(logger.Discard)).Load(order)
+	require.NoError(t, err)
+	require.Equal(t, "CREATE TABLE `orders` (`id` integer PRIMARY KEY AUTOINCREMENT NOT NULL,`customer_id` integer,`status` text,`total` integer);\n"+
+		"DROP INDEX IF EXISTS `idx_orders_customer`;\n"+
+		"CREATE INDEX `idx_orders_customer` ON `orders`(`customer_id`);\n"+
+		"CREATE INDEX `idx_orders_status` ON `orders`(`status`);\n"+
+		"DROP INDEX IF EXISTS `idx_orders_total`;\n"+
+		"CREATE INDEX `idx_orders_total` ON `orders`(`total`);\n", sql)
+
+	resetSession()
+	sql, err = gormschema.New("sqlserver").Load(order)
+	require.NoError(t, err)
+	require.Contains(t, sql, `DROP INDEX IF EXISTS "idx_orders_total" ON "orders";
+CREATE INDEX "idx_orders_total" ON "orders"("total");`)
+
+	resetSession()
+	_, err = gormschema.New("mysql").Load(order)
+	require.EqualError(t, err, `index "idx_orders_total": replacing indexes is not supported by mysql`)
+
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "uq_orders_status", Columns: []gormschema.Col[Order]{status}, Unique: true, Style: gormschema.AlterConstraint, Replace: true},
+	}
+	resetSession()
+	_, err = gormschema.New("postgres").Load(order)
+	require.EqualError(t, err, `index "uq_orders_status": constraints cannot be replaced`)
+	resetSession()
+}
+
+func TestSQLiteCollations(t *testing.T) {
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{gormschema.Collate(status, "nocase")}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("sqlite").Load(order)
+	require.NoError(t, err)
+	require.Contains(t, sql, "CREATE INDEX `idx_orders_status` ON `orders`(`status` COLLATE NOCASE);")
+
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{gormschema.Collate(status, "C")}},
+	}
+	resetSession()
+	_, err = gormschema.New("sqlite").Load(order)
+	require.EqualError(t, err, `index "idx_orders_status" column 1: collation "C" is not supported by sqlite, expected BINARY, NOCASE or RTRIM`)
+	resetSession()
+	sql, err = gormschema.New("postgres").Load(order)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_status" ON "orders" ("status" COLLATE "C");`)
+	resetSession()
+}
+
+func TestCollate(t *testing.T) {
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	for _, col := range []gormschema.Col[Order]{
+		gormschema.Collate(gormschema.Desc(gormschema.NullsLast(status)), "C"),
+		gormschema.NullsLast(gormschema.Desc(gormschema.Collate(status, "C"))),
+	} {
+		order := Order{indexes: []gormschema.IndexDefinition[Order]{
+			{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{col}},
+			{Name: "idx_orders_status_prefix", Columns: []gormschema.Col[Order]{gormschema.Class(gormschema.Collate(status, "C"), "text_pattern_ops")}},
+		}}
+		resetSession()
+		sql, err := gormschema.New("postgres").Load(order)
+		require.NoError(t, err)
+		require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_status" ON "orders" ("status" COLLATE "C" desc nulls last);`)
+		require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_status_prefix" ON "orders" ("status" COLLATE "C" text_pattern_ops);`)
+	}
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{gormschema.Desc(gormschema.Collate(status, "utf8mb4_bin"))}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("mysql").Load(order)
+	require.NoError(t, err)
+	require.Contains(t, sql, "INDEX `idx_orders_status` ((`status` COLLATE utf8mb4_bin) desc)")
+	resetSession()
+	_, err = gormschema.New("sqlserver").Load(order)
+	require.EqualError(t, err, `index "idx_orders_status" column 1: collations of index columns are not supported by sqlserver`)
+
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{gormschema.Collate(status, `C"; DROP TABLE orders`)}},
+	}
+	resetSession()
+	_, err = gormschema.New("postgres").Load(order)
+	require.EqualError(t, err, `index "idx_orders_status" column 1: invalid collation "C\"; DROP TABLE orders"`)
+	resetSession()
+}
+
+func TestWithContentHashedNames(t *testing.T) {
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	hashed := regexp.MustCompile(`"(idx_orders_status_[0-9a-f]{8})"`)
+	name := func(models ...any) string {
+		resetSession()
+		sql, err := gormschema.New("postgres", gormschema.WithContentHashedNames()).Load(models...)
+		require.NoError(t, err)
+		m := hashed.FindStringSubmatch(sql)
+		require.NotNil(t, m, sql)
+		return m[1]
+	}
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}},
+	}}
+	plain := name(order)
+	require.Equal(t, plain, name(Note{}, order))
+	require.Equal(t, plain, name(order, Note{}))
+
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{gormschema.Class(status, "text_pattern_ops")}},
+	}
+	pattern := name(order)
+	require.NotEqual(t, plain, pattern)
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{gormschema.Class(status, "varchar_pattern_ops")}},
+	}
+	require.NotEqual(t, pattern, name(order))
+
+	// Names hash the columns, not the fields, and are truncated to the identifier limit of PostgreSQL.
+	hashed = regexp.MustCompile(`"(idx_parkings_\w+)"`)
+	names := func(model any) []string {
+		resetSession()
+		sql, err := gormschema.New("postgres", gormschema.WithContentHashedNames()).Load(model)
+		require.NoError(t, err)
+		var names []string
+		for _, m := range hashed.FindAllStringSubmatch(sql, -1) {
+			names = append(names, m[1])
+		}
+		require.Len(t, names, 2, sql)
+		return names
+	}
+	parking := names(Parking{})
+	require.Equal(t, parking, names(RenamedParking{}))
+	require.Len(t, parking[1], 63)
+	require.Regexp(t, `^idx_parkings_status_x+_[0-9a-f]{8}$`, parking[1])
+	resetSession()
+}
+
+// parkingIndex is long enough for its hashed name to exceed the identifier limit of PostgreSQL.
+var parkingIndex = "idx_{table}_status_" + strings.Repeat("x", 50)
+
+type Parking struct {
+	ID     uint
+	Status string
+}
+
+func (Parking) Indexes() []gormschema.IndexDefinition[Parking] {
+	status := gormschema.Field(func(m *Parking) any { return &m.Status })
+	return []gormschema.IndexDefinition[Parking]{
+		{Name: "idx_{table}_status", Columns: []gormschema.Col[Parking]{status}},
+		{Name: parkingIndex, Columns: []gormschema.Col[Parking]{status}, Where: "status <> 'free'"},
+	}
+}
+
+// RenamedParking is Parking with its Status field renamed, keeping its column.
+type RenamedParking struct {
+	ID    uint
+	State string `gorm:"column:status"`
+}
+
+func (RenamedParking) TableName() string { return "parkings" }
+
+func (RenamedParking) Indexes() []gormschema.IndexDefinition[RenamedParking] {
+	state := gormschema.Field(func(m *RenamedParking) any { return &m.State })
+	return []gormschema.IndexDefinition[RenamedParking]{
+		{Name: "idx_{table}_status", Columns: []gormschema.Col[RenamedParking]{state}},
+		{Name: parkingIndex, Columns: []gormschema.Col[RenamedParking]{state}, Where: "status <> 'free'"},
+	}
+}
+
+type Listing struct {
+	ID    uint
+	Group string
+	Order bool
+}
+
+func (Listing) Indexes() []gormschema.IndexDefinition[Listing] {
+	return []gormschema.IndexDefinition[Listing]{
+		{
+			Name:    "idx_listings_group",
+			Columns: []gormschema.Col[Listing]{gormschema.Desc(gormschema.Field(func(m *Listing) any { return &m.Group }))},
+			Where:   "order",
+		},
+	}
+}
+
+func TestReservedWords(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres":  `CREATE INDEX IF NOT EXISTS "idx_listings_group" ON "listings" ("group" desc) WHERE "order";`,
+		"sqlite":    "CREATE INDEX `idx_listings_group` ON `listings`(`group` desc) WHERE `order` = 1;",
+		"sqlserver": `CREATE INDEX "idx_listings_group" ON "listings"("group" desc) WHERE "order" = 1;`,
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Listing{})
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+			resetSession()
+		})
+	}
+}
+
+type Shipment struct {
+	ID      uint
+	Carrier string
+	Label   string `gorm:"-"`
+}
+
+func (Shipment) Indexes() []gormschema.IndexDefinition[Shipment] {
+	return []gormschema.IndexDefinition[Shipment]{
+		{
+			Name: "idx_shipments_carrier",
+			Columns: []gormschema.Col[Shipment]{
+				gormschema.Field(func(m *Shipment) any { return &m.Carrier }),
+				gormschema.Field(func(m *Shipment) any { return new(string) }),
+			},
+		},
+		{
+			Name:    "idx_shipments_label",
+			Columns: []gormschema.Col[Shipment]{gormschema.Field(func(m *Shipment) any { return &m.Label })},
+		},
+	}
+}
+
+func TestSelectorErrors(t *testing.T) {
+	resetSession()
+	_, err := gormschema.New("postgres").Load(Shipment{})
+	require.EqualError(t, err, `index "idx_shipments_carrier" column 2: Sel returned a field of a different value than its *Shipment argument; the closure must use its own parameter`+"\n"+
+		`index "idx_shipments_label" column 1: field "Label" is not mapped to a column`)
+	resetSession()
+}
+
+type Embedding struct {
+	ID     uint
+	Vector string `gorm:"type:vector(3)"`
+}
+
+func (Embedding) Indexes() []gormschema.IndexDefinition[Embedding] {
+	return []gormschema.IndexDefinition[Embedding]{
+		{Name: "idx_embeddings_vector", Columns: []gormschema.Col[Embedding]{gormschema.Class(gormschema.Field(func(m *Embedding) any { return &m.Vector }), "vector_l2_ops")}},
+	}
+}
+
+func TestKnownOpClasses(t *testing.T) {
+	known := gormschema.WithKnownOpClasses(map[string]gormschema.OpClassInfo{
+		"vector_l2_ops": {Method: "hnsw", Extension: "vector"},
+	})
+	resetSession()
+	sql, err := gormschema.New("postgres", known).Load(Embedding{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE EXTENSION IF NOT EXISTS "vector";
+CREATE TABLE "embeddings" ("id" bigserial NOT NULL,"vector" vector(3),PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_embeddings_vector" ON "embeddings" USING hnsw("vector" vector_l2_ops);
+`, sql)
+	resetSession()
+	// Builtin operator classes are still known.
+	sql, err = gormschema.New("postgres", known).Load(Document{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE EXTENSION IF NOT EXISTS "pg_trgm";`)
+	resetSession()
+	exts, err := gormschema.New("postgres", known).ExtractRequiredExtensions(Document{}, Embedding{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"pg_trgm", "vector"}, exts)
+	// Operator classes are known only to the loaders they are registered with.
+	exts, err = gormschema.New("postgres").ExtractRequiredExtensions(Document{}, Embedding{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"pg_trgm"}, exts)
+}
+
+func TestUnknownOpClass(t *testing.T) {
+	title := gormschema.Field(func(m *Article) any { return &m.Title })
+	article := Article{indexes: []gormschema.IndexDefinition[Article]{
+		{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{gormschema.Class(title, "gin_trgm_op")}},
+	}}
+	resetSession()
+	l := &warnLogger{Interface: logger.Discard}
+	_, err := gormschema.New("postgres", gormschema.WithLogger(l)).Load(article)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`index "idx_articles_title" column 1: unknown operator class "gin_trgm_op", register custom operator classes using WithKnownOpClasses`,
+	}, l.warns)
+
+	resetSession()
+	_, err = gormschema.New("postgres", gormschema.WithStrictOpClasses()).Load(article)
+	require.EqualError(t, err, `index "idx_articles_title" column 1: unknown operator class "gin_trgm_op"`)
+
+	// Known, multi-method and qualified operator classes are accepted in strict mode.
+	for _, opclass := range []string{"gin_trgm_ops", "text_pattern_ops", "app.title_ops"} {
+		article.indexes = []gormschema.IndexDefinition[Article]{
+			{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{gormschema.Class(title, opclass)}},
+		}
+		resetSession()
+		_, err = gormschema.New("postgres", gormschema.WithStrictOpClasses()).Load(article)
+		require.NoError(t, err)
+	}
+	article.indexes = []gormschema.IndexDefinition[Article]{
+		{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{gormschema.Class(title, "title_ops")}},
+	}
+	resetSession()
+	known := gormschema.WithKnownOpClasses(map[string]gormschema.OpClassInfo{"title_ops": {Method: "gist"}})
+	sql, err := gormschema.New("postgres", gormschema.WithStrictOpClasses(), known).Load(article)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_articles_title" ON "articles" USING gist("title" title_ops);`)
+	resetSession()
+}
+
+type Issue struct {
+	ID       uint
+	Title    string
+	Priority int
+}
+
+func (Issue) Indexes() []gormschema.IndexDefinition[Issue] {
+	title := gormschema.Field(func(m *Issue) any { return &m.Title })
+	priority := gormschema.Field(func(m *Issue) any { return &m.Priority })
+	return []gormschema.IndexDefinition[Issue]{
+		{Name: "idx_issues_priority_title", Columns: []gormschema.Col[Issue]{gormschema.Desc(priority), title}},
+		{Name: "idx_issues_title_pattern", Columns: []gormschema.Col[Issue]{gormschema.Class(gormschema.NullsLast(title), "text_pattern_ops"), gormschema.NullsFirst(priority)}},
+		{Name: "idx_issues_title_trgm", Columns: []gormschema.Col[Issue]{gormschema.Class(title, "gin_trgm_ops")}},
+	}
+}
+
+func TestWithExplicitSortDirection(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithExplicitSortDirection()).Load(Issue{})
+	require.NoError(t, err)
+	requireEqualContent(t, sql, "testdata/postgresql_explicit_sort.sql")
+	resetSession()
+}
+
+func TestWhereForeignReference(t *testing.T) {
+	customer := gormschema.Field(func(m *Order) any { return &m.CustomerID })
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Where: `"customers"."active" = true`},
+	}}
+	resetSession()
+	_, err := gormschema.New("postgres").Load(order)
+	require.EqualError(t, err, `index "idx_orders_customer": where references column "customers.active" of another table`)
+	// Columns of the indexed table might be qualified, and literals are ignored.
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Where: `orders.total > 0 AND status <> 'customers.closed'`},
+	}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(order)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_customer" ON "orders" ("customer_id") WHERE orders.total > 0 AND status <> 'customers.closed';`)
+	// Calls of functions qualified with their schema are not column references.
+	for _, where := range []string{
+		`pg_catalog.lower(status) <> 'x'`,
+		`total > public.threshold()`,
+		`total > "public"."threshold" ()`,
+	} {
+		order.indexes = []gormschema.IndexDefinition[Order]{
+			{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Where: where},
+		}
+		resetSession()
+		sql, err = gormschema.New("postgres").Load(order)
+		require.NoError(t, err, where)
+		require.Contains(t, sql, "WHERE "+where+";")
+	}
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, Where: `public.threshold() < customers.total`},
+	}
+	resetSession()
+	_, err = gormschema.New("postgres").Load(order)
+	require.EqualError(t, err, `index "idx_orders_customer": where references column "customers.total" of another table`)
+	resetSession()
+}
+
+// Attributes is a custom JSON data type.
+type Attributes map[string]any
+
+func (a Attributes) Value() (driver.Value, error) { return json.Marshal(a) }
+
+func (a *Attributes) Scan(v any) error {
+	b, ok := v.([]byte)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", v)
+	}
+	return json.Unmarshal(b, a)
+}
+
+func (Attributes) GormDataType() string { return "json" }
+
+func (Attributes) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "json"
+}
+
+type Item struct {
+	ID         uint
+	Attributes Attributes
+}
+
+func (Item) Indexes() []gormschema.IndexDefinition[Item] {
+	return []gormschema.IndexDefinition[Item]{
+		{Name: "idx_items_attributes", Type: "gin", Columns: []gormschema.Col[Item]{gormschema.Field(func(m *Item) any { return &m.Attributes })}},
+	}
+}
+
+func TestCustomDataTypes(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Item{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "items" ("id" bigserial NOT NULL,"attributes" jsonb,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_items_attributes" ON "items" USING gin("attributes");
+`, sql)
+	resetSession()
+}
+
+type ArchivedUser struct {
+	gorm.Model
+	Email string
+}
+
+func (ArchivedUser) Indexes() []gormschema.IndexDefinition[ArchivedUser] {
+	return []gormschema.IndexDefinition[ArchivedUser]{
+		{
+			Name:    "idx_archived_users_email",
+			Columns: []gormschema.Col[ArchivedUser]{gormschema.Field(func(m *ArchivedUser) any { return &m.Email })},
+			Unique:  true,
+			Where:   "deleted_at IS NOT NULL",
+		},
+	}
+}
+
+func TestNotNullWhere(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres": `CREATE UNIQUE INDEX IF NOT EXISTS "idx_archived_users_email" ON "archived_users" ("email") WHERE deleted_at IS NOT NULL;`,
+		"sqlite":   "CREATE UNIQUE INDEX `idx_archived_users_email` ON `archived_users`(`email`) WHERE deleted_at IS NOT NULL;",
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(ArchivedUser{})
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+			resetSession()
+		})
+	}
+}
+
+type Mailbox struct {
+	ID    uint
+	Email string `gorm:"type:citext"`
+}
+
+func (Mailbox) RequiredExtensions() []string {
+	return []string{"citext"}
+}
+
+func TestRequiredExtensions(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithExtensions("uuid-ossp", "pg_trgm")).Load(Mailbox{}, Document{})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(sql, `CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+CREATE EXTENSION IF NOT EXISTS "citext";
+`), sql)
+	require.Contains(t, sql, `"email" citext`)
+	resetSession()
+	exts, err := gormschema.New("postgres").ExtractRequiredExtensions(Mailbox{}, Document{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"citext", "pg_trgm"}, exts)
+	exts, err = gormschema.New("postgres", gormschema.WithExtensions("uuid-ossp", "pg_trgm")).ExtractRequiredExtensions(Mailbox{}, Document{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"uuid-ossp", "pg_trgm", "citext"}, exts)
+}
+
+type Directory struct {
+	ID    uint
+	Email string `gorm:"type:citext"`
+	Attrs string `gorm:"type:hstore"`
+}
+
+func (Directory) RequiredExtensions() []string {
+	return []string{"hstore", "citext"}
+}
+
+func (Directory) Indexes() []gormschema.IndexDefinition[Directory] {
+	return []gormschema.IndexDefinition[Directory]{
+		{Name: "idx_directories_attrs", Columns: []gormschema.Col[Directory]{gormschema.Class(gormschema.Field(func(m *Directory) any { return &m.Attrs }), "gin_hstore_ops")}},
+	}
+}
+
+func TestRequiredExtensionsOrder(t *testing.T) {
+	// The extensions are sorted regardless of the order of the models.
+	want := []string{"citext", "hstore", "pg_trgm"}
+	for _, models := range [][]any{{Document{}, Directory{}, Mailbox{}}, {Mailbox{}, Directory{}, Document{}}} {
+		exts, err := gormschema.New("postgres").ExtractRequiredExtensions(models...)
+		require.NoError(t, err)
+		require.Equal(t, want, exts)
+	}
+	for _, models := range [][]any{{Document{}, Directory{}, Mailbox{}}, {Mailbox{}, Directory{}, Document{}}} {
+		resetSession()
+		sql, err := gormschema.New("postgres").Load(models...)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(sql, `CREATE EXTENSION IF NOT EXISTS "citext";
+CREATE EXTENSION IF NOT EXISTS "hstore";
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+CREATE TABLE `), sql)
+	}
+	// Extensions of WithExtensions keep their order, and precede the ones of the models.
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithExtensions("uuid-ossp", "hstore")).Load(Mailbox{}, Directory{}, Document{})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(sql, `CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS "hstore";
+CREATE EXTENSION IF NOT EXISTS "citext";
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+`), sql)
+	resetSession()
+}
+
+type PinnedMailbox struct {
+	ID    uint
+	Email string `gorm:"type:citext"`
+}
+
+func (PinnedMailbox) RequiredExtensions() []string {
+	return []string{"citext:1.6"}
+}
+
+func TestExtensionVersions(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithExtensions("pg_trgm:1.6", "uuid-ossp")).Load(PinnedMailbox{}, Document{})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(sql, `CREATE EXTENSION IF NOT EXISTS "pg_trgm" VERSION '1.6';
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS "citext" VERSION '1.6';
+CREATE TABLE `), sql)
+	resetSession()
+	sql, err = gormschema.New("postgres").LoadDown(PinnedMailbox{})
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(sql, "DROP EXTENSION IF EXISTS \"citext\";\n"), sql)
+	// Unversioned requirements of pinned extensions use their versions.
+	exts, err := gormschema.New("postgres").ExtractRequiredExtensions(Mailbox{}, Document{}, PinnedMailbox{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"citext:1.6", "pg_trgm"}, exts)
+
+	resetSession()
+	_, err = gormschema.New("postgres", gormschema.WithExtensions("citext:1.5")).Load(PinnedMailbox{})
+	require.EqualError(t, err, `extension "citext" is required with versions 1.5 and 1.6`)
+	_, err = gormschema.New("postgres", gormschema.WithExtensions("citext:1.5")).ExtractRequiredExtensions(PinnedMailbox{})
+	require.EqualError(t, err, `extension "citext" is required with versions 1.5 and 1.6`)
+	resetSession()
+	_, err = gormschema.New("postgres", gormschema.WithExtensions("pg_trgm:1.6'; DROP TABLE users; --")).Load(Document{})
+	require.EqualError(t, err, `extension "pg_trgm": invalid version "1.6'; DROP TABLE users; --"`)
+	resetSession()
+}
+
+type Wide struct {
+	ID                                                                         uint
+	C1, C2, C3, C4, C5, C6, C7, C8, C9, C10, C11, C12, C13, C14, C15, C16, C17 int
+	// columns is the number of columns of the index.
+	columns int
+}
+
+func (w Wide) Indexes() []gormschema.IndexDefinition[Wide] {
+	cols := make([]gormschema.Col[Wide], w.columns)
+	for i := range cols {
+		cols[i] = gormschema.Field(func(m *Wide) any { return reflect.ValueOf(m).Elem().Field(i + 1).Addr().Interface() })
+	}
+	return []gormschema.IndexDefinition[Wide]{{Name: "idx_wides_columns", Columns: cols}}
+}
+
+func TestIndexColumnLimits(t *testing.T) {
+	resetSession()
+	_, err := gormschema.New("mysql").Load(Wide{columns: 17})
+	require.EqualError(t, err, `index "idx_wides_columns": 17 columns exceed the limit of 16 columns per index of mysql`)
+	resetSession()
+	sql, err := gormschema.New("mysql").Load(Wide{columns: 16})
+	require.NoError(t, err)
+	require.Contains(t, sql, "INDEX `idx_wides_columns` (`c1`,`c2`,`c3`,`c4`,`c5`,`c6`,`c7`,`c8`,`c9`,`c10`,`c11`,`c12`,`c13`,`c14`,`c15`,`c16`)")
+	resetSession()
+}
+
+type Post struct {
+	ID    uint
+	Title string
+	Body  string
+	// config is the text search configuration of the body index, "english" by default.
+	config string
+}
+
+func (p Post) Indexes() []gormschema.IndexDefinition[Post] {
+	return []gormschema.IndexDefinition[Post]{
+		{
+			Name: "idx_posts_body_search",
+			Type: "gin",
+			Columns: []gormschema.Col[Post]{
+				gormschema.TSVector(cmp.Or(p.config, "english"), func(m *Post) any { return &m.Body }),
+			},
+		},
+		{
+			Name: "idx_posts_search",
+			Type: "gin",
+			Columns: []gormschema.Col[Post]{
+				gormschema.TSVector("pg_catalog.simple", func(m *Post) any { return &m.Title }, func(m *Post) any { return &m.Body }),
+			},
+		},
+	}
+}
+
+func TestTSVector(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Post{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_posts_body_search" ON "posts" USING gin(to_tsvector('english', "body"));`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_posts_search" ON "posts" USING gin(to_tsvector('pg_catalog.simple', coalesce("title", '') || ' ' || coalesce("body", '')));`)
+	for _, dialect := range []string{"mysql", "sqlite", "sqlserver"} {
+		resetSession()
+		_, err = gormschema.New(dialect).Load(Post{})
+		require.EqualError(t, err, `index "idx_posts_body_search" column 1: text search vectors are supported only by PostgreSQL`, dialect)
+	}
+	resetSession()
+	_, err = gormschema.New("postgres").Load(Post{config: "english'); DROP TABLE posts; --"})
+	require.EqualError(t, err, `index "idx_posts_body_search" column 1: invalid text search configuration "english'); DROP TABLE posts; --"`)
+	resetSession()
+}
+
+type Reading struct {
+	ID       uint
+	SensorID uint
+	TakenAt  time.Time
+	// clusterTaken clusters the table on the index of TakenAt as well.
+	clusterTaken bool
+}
+
+func (r Reading) Indexes() []gormschema.IndexDefinition[Reading] {
+	return []gormschema.IndexDefinition[Reading]{
+		{
+			Name: "idx_readings_sensor_taken",
+			Columns: []gormschema.Col[Reading]{
+				gormschema.Field(func(m *Reading) any { return &m.SensorID }),
+				gormschema.Field(func(m *Reading) any { return &m.TakenAt }),
+			},
+			Cluster: true,
+		},
+		{
+			Name:    "idx_readings_taken",
+			Columns: []gormschema.Col[Reading]{gormschema.Field(func(m *Reading) any { return &m.TakenAt })},
+			Cluster: r.clusterTaken,
+		},
+	}
+}
+
+func TestClusterIndex(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Reading{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_readings_taken" ON "readings" ("taken_at");
+CLUSTER "readings" USING "idx_readings_sensor_taken";
+`)
+	resetSession()
+	_, err = gormschema.New("mysql").Load(Reading{})
+	require.EqualError(t, err, `index "idx_readings_sensor_taken": clustering indexes are supported only by PostgreSQL`)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(Reading{clusterTaken: true})
+	require.EqualError(t, err, `index "idx_readings_taken": readings is already clustered on index "idx_readings_sensor_taken"`)
+	resetSession()
+}
+
+type Metric struct {
+	ID       uint
+	Name     string
+	Archived bool
+}
+
+func (Metric) Indexes() []gormschema.IndexDefinition[Metric] {
+	return []gormschema.IndexDefinition[Metric]{
+		{
+			Name:    "idx_metrics_name",
+			Columns: []gormschema.Col[Metric]{gormschema.Field(func(m *Metric) any { return &m.Name })},
+			Where:   "archived = false",
+		},
+		{
+			Name:       "idx_metrics_archived",
+			Columns:    []gormschema.Col[Metric]{gormschema.Field(func(m *Metric) any { return &m.Archived })},
+			Tablespace: "fast",
+		},
+	}
+}
+
+func TestWithDefaultTablespace(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithDefaultTablespace("bulk")).Load(Metric{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "metrics" ("id" bigserial NOT NULL,"name" text,"archived" boolean,PRIMARY KEY ("id")) TABLESPACE "bulk";
+CREATE INDEX IF NOT EXISTS "idx_metrics_archived" ON "metrics" ("archived") TABLESPACE "fast";
+CREATE INDEX IF NOT EXISTS "idx_metrics_name" ON "metrics" ("name") TABLESPACE "bulk" WHERE archived = false;
+`, sql)
+	resetSession()
+	_, err = gormschema.New("sqlite", gormschema.WithDefaultTablespace("bulk")).Load(Metric{})
+	require.EqualError(t, err, `index "idx_metrics_archived": tablespaces are supported only by PostgreSQL`)
+	resetSession()
+	sql, err = gormschema.New("mysql", gormschema.WithDefaultTablespace("bulk")).Load(Issue{})
+	require.NoError(t, err)
+	require.NotContains(t, sql, "TABLESPACE")
+	resetSession()
+}
+
+type Visit struct {
+	ID        uint
+	SiteID    uint `gorm:"uniqueIndex:idx_visits_site_unique"`
+	VisitedAt time.Time
+}
+
+func (Visit) Indexes() []gormschema.IndexDefinition[Visit] {
+	return []gormschema.IndexDefinition[Visit]{
+		{
+			Name:    "idx_visits_site",
+			Columns: []gormschema.Col[Visit]{gormschema.Field(func(m *Visit) any { return &m.SiteID })},
+		},
+		{
+			Name: "idx_visits_site_visited",
+			Columns: []gormschema.Col[Visit]{
+				gormschema.Field(func(m *Visit) any { return &m.SiteID }),
+				gormschema.Field(func(m *Visit) any { return &m.VisitedAt }),
+			},
+		},
+	}
+}
+
+// warnLogger records the warnings logged by the loader.
+type warnLogger struct {
+	logger.Interface
+	warns []string
+}
+
+func (l *warnLogger) Warn(_ context.Context, msg string, args ...any) {
+	l.warns = append(l.warns, fmt.Sprintf(msg, args...))
+}
+
+func TestWithRedundantIndexWarnings(t *testing.T) {
+	resetSession()
+	l := &warnLogger{Interface: logger.Discard}
+	_, err := gormschema.New("postgres", gormschema.WithLogger(l), gormschema.WithRedundantIndexWarnings()).Load(Visit{})
+	require.NoError(t, err)
+	require.Equal(t, []string{`index "idx_visits_site" of table "visits" is a prefix of index "idx_visits_site_visited" and might be redundant`}, l.warns)
+	resetSession()
+	l.warns = nil
+	_, err = gormschema.New("postgres", gormschema.WithLogger(l)).Load(Visit{})
+	require.NoError(t, err)
+	require.Empty(t, l.warns)
+	resetSession()
+}
+
+type Customer struct {
+	ID       uint
+	TenantID uint
+	Handle   string
+	Email    string
+	Slug     string
+}
+
+func (Customer) Indexes() []gormschema.IndexDefinition[Customer] {
+	return []gormschema.IndexDefinition[Customer]{
+		{
+			Name:    "idx_customers_email",
+			Columns: []gormschema.Col[Customer]{gormschema.Field(func(m *Customer) any { return &m.Email })},
+			Unique:  true,
+		},
+		{
+			Name: "idx_customers_tenant_handle",
+			Columns: []gormschema.Col[Customer]{
+				gormschema.Field(func(m *Customer) any { return &m.TenantID }),
+				gormschema.Field(func(m *Customer) any { return &m.Handle }),
+			},
+			Unique: true,
+		},
+		{
+			Name:    "idx_customers_slug",
+			Columns: []gormschema.Col[Customer]{gormschema.Field(func(m *Customer) any { return &m.Slug })},
+			Unique:  true,
+			Where:   "slug <> ''",
+		},
+	}
+}
+
+func TestUniqueConstraints(t *testing.T) {
+	sets, err := gormschema.New("postgres").UniqueConstraints(Customer{}, Post{})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"customers": {"email", "tenant_id,handle"},
+	}, sets)
+	// Tables are named by the loader, and definitions are validated for its dialect.
+	sets, err = gormschema.New("postgres", gormschema.WithSchema("app")).UniqueConstraints(Customer{})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"app.customers": {"email", "tenant_id,handle"},
+	}, sets)
+	_, err = gormschema.New("mysql").UniqueConstraints(Customer{})
+	require.EqualError(t, err, `index "idx_customers_slug": partial indexes are not supported by mysql`)
+	_, err = gormschema.New("postgres").UniqueConstraints(InvalidShape{})
+	require.EqualError(t, err, `index "idx_invalid_shapes_area" column 1: invalid operator class "area_ops) WHERE (true"`)
+}
+
+type Parcel struct {
+	ID   uint
+	Code string
+	sel  func(*Parcel) any
+}
+
+func (p Parcel) Indexes() []gormschema.IndexDefinition[Parcel] {
+	return []gormschema.IndexDefinition[Parcel]{
+		{Name: "idx_parcels_code", Columns: []gormschema.Col[Parcel]{gormschema.Field(p.sel)}},
+	}
+}
+
+func TestSelectorUnwrapping(t *testing.T) {
+	for name, sel := range map[string]func(*Parcel) any{
+		"pointer":   func(m *Parcel) any { return &m.Code },
+		"interface": func(m *Parcel) any { return any(&m.Code) },
+		"wrapped": func(m *Parcel) any {
+			var code any = &m.Code
+			return &code
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New("postgres").Load(Parcel{sel: sel})
+			require.NoError(t, err)
+			require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_parcels_code" ON "parcels" ("code");`)
+			resetSession()
+		})
+	}
+	var other Parcel
+	for _, tt := range []struct {
+		sel      func(*Parcel) any
+		expected string
+	}{
+		{func(m *Parcel) any { return m }, "Sel didn't point to a top-level exported field on Parcel"},
+		{func(*Parcel) any { return &other.Code }, "Sel returned a field of a different value than its *Parcel argument; the closure must use its own parameter"},
+		{func(m *Parcel) any { return m.Code }, "Sel must return a *field (pointer), got string"},
+		{func(m *Parcel) any { return nil }, "Sel returned a nil interface"},
+	} {
+		resetSession()
+		_, err := gormschema.New("postgres").Load(Parcel{sel: tt.sel})
+		require.EqualError(t, err, `index "idx_parcels_code" column 1: `+tt.expected)
+		resetSession()
+	}
+}
+
+type Setting struct {
+	ID   uint
+	Data string `gorm:"type:jsonb"`
+	Name string
+	// path is the json path of the owner index, "owner.age" by default.
+	path string
+}
+
+func (s Setting) Indexes() []gormschema.IndexDefinition[Setting] {
+	return []gormschema.IndexDefinition[Setting]{
+		{
+			Name:    "idx_settings_theme",
+			Columns: []gormschema.Col[Setting]{gormschema.JSONPath(func(m *Setting) any { return &m.Data }, "theme", "")},
+		},
+		{
+			Name:    "idx_settings_owner_age",
+			Columns: []gormschema.Col[Setting]{gormschema.JSONPath(func(m *Setting) any { return &m.Data }, cmp.Or(s.path, "owner.age"), "integer")},
+		},
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Setting{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_settings_owner_age" ON "settings" ((("data"#>>'{owner,age}')::integer));
+CREATE INDEX IF NOT EXISTS "idx_settings_theme" ON "settings" (("data"->>'theme'));
+`)
+	resetSession()
+	_, err = gormschema.New("mysql").Load(Setting{})
+	require.EqualError(t, err, `index "idx_settings_theme" column 1: json paths are supported only by PostgreSQL`)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(Setting{path: "owner'age"})
+	require.EqualError(t, err, `index "idx_settings_owner_age" column 1: invalid json path "owner'age"`)
+	resetSession()
+}
+
+type InvalidSetting struct {
+	ID   uint
+	Name string
+}
+
+func (InvalidSetting) Indexes() []gormschema.IndexDefinition[InvalidSetting] {
+	return []gormschema.IndexDefinition[InvalidSetting]{
+		{
+			Name:    "idx_invalid_settings_name",
+			Columns: []gormschema.Col[InvalidSetting]{gormschema.JSONPath(func(m *InvalidSetting) any { return &m.Name }, "a", "")},
+		},
+	}
+}
+
+func TestJSONPathColumnType(t *testing.T) {
+	resetSession()
+	_, err := gormschema.New("postgres").Load(InvalidSetting{})
+	require.EqualError(t, err, `index "idx_invalid_settings_name" column 1: column "name" of type text is not a json or jsonb column`)
+	resetSession()
+}
+
+type Voucher struct {
+	ID        uint
+	Code      string
+	ExpiresAt time.Time
+	where     string
+}
+
+func (v Voucher) Indexes() []gormschema.IndexDefinition[Voucher] {
+	return []gormschema.IndexDefinition[Voucher]{
+		{
+			Name:    "idx_vouchers_code",
+			Columns: []gormschema.Col[Voucher]{gormschema.Field(func(m *Voucher) any { return &m.Code })},
+			Unique:  true,
+			Where:   v.where,
+		},
+	}
+}
+
+func TestVolatileWhere(t *testing.T) {
+	for where, expected := range map[string]string{
+		"expires_at > now()":             `index "idx_vouchers_code": where of a unique index must be immutable, but calls now()`,
+		"expires_at > CURRENT_TIMESTAMP": `index "idx_vouchers_code": where of a unique index must be immutable, but calls current_timestamp`,
+		"code <> 'now()'":                "",
+		"expires_at > '2030-01-01'":      "",
+	} {
+		resetSession()
+		sql, err := gormschema.New("postgres").Load(Voucher{where: where})
+		if expected != "" {
+			require.EqualError(t, err, expected)
+			continue
+		}
+		require.NoError(t, err)
+		require.Contains(t, sql, `CREATE UNIQUE INDEX IF NOT EXISTS "idx_vouchers_code" ON "vouchers" ("code") WHERE `+where+";")
+	}
+	resetSession()
+}
+
+type Lead struct {
+	ID    uint
+	Email string
+}
+
+func (Lead) Indexes() []gormschema.IndexDefinition[Lead] {
+	return []gormschema.IndexDefinition[Lead]{
+		{
+			Name:    "idx_leads_email_pattern",
+			Columns: []gormschema.Col[Lead]{gormschema.Class(gormschema.Expr[Lead]("lower(email)::text"), "text_pattern_ops")},
+		},
+		{
+			Name: "idx_leads_email_desc",
+			Columns: []gormschema.Col[Lead]{
+				gormschema.Desc(gormschema.Class(gormschema.Expr("lower({1})", func(m *Lead) any { return &m.Email }), "text_pattern_ops")),
+			},
+		},
+	}
+}
+
+func TestExprOpClass(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Lead{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_leads_email_desc" ON "leads" (lower("email") text_pattern_ops desc);
+CREATE INDEX IF NOT EXISTS "idx_leads_email_pattern" ON "leads" ((lower(email)::text) text_pattern_ops);
+`)
+	resetSession()
+}
+
+type Team struct {
+	ID     uint
+	Name   string `gorm:"index"`
+	Region string
+}
+
+func (Team) TableName() string { return "SalesTeams" }
+
+func (Team) Indexes() []gormschema.IndexDefinition[Team] {
+	return []gormschema.IndexDefinition[Team]{
+		{
+			Name:    "IDX_SalesTeams_Region",
+			Columns: []gormschema.Col[Team]{gormschema.Field(func(m *Team) any { return &m.Region })},
+		},
+	}
+}
+
+func TestIndexNameCaseFolding(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Team{})
+	require.NoError(t, err)
+	// Generated names are folded like unquoted identifiers, and explicit names are kept as-is.
+	require.Equal(t, `CREATE TABLE "SalesTeams" ("id" bigserial NOT NULL,"name" text,"region" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "IDX_SalesTeams_Region" ON "SalesTeams" ("region");
+CREATE INDEX IF NOT EXISTS "idx_salesteams_name" ON "SalesTeams" ("name");
+`, sql)
+	resetSession()
+	sql, err = gormschema.New("mysql").Load(Team{})
+	require.NoError(t, err)
+	require.Contains(t, sql, "INDEX `idx_SalesTeams_name` (`name`)")
+	resetSession()
+}
+
+type NotebookFile struct {
+	ID   uint
+	Name string
+	Path string
+}
+
+func (NotebookFile) Indexes() []gormschema.IndexDefinition[NotebookFile] {
+	return []gormschema.IndexDefinition[NotebookFile]{
+		{
+			Name:    "idx_notebook_files_name_trgm",
+			Columns: []gormschema.Col[NotebookFile]{gormschema.Class(gormschema.Field(func(m *NotebookFile) any { return &m.Name }), "gin_trgm_ops")},
+		},
+		{
+			Name:    "idx_notebook_files_path_trgm",
+			Columns: []gormschema.Col[NotebookFile]{gormschema.Class(gormschema.Field(func(m *NotebookFile) any { return &m.Path }), "gist_trgm_ops")},
+		},
+	}
+}
+
+func TestWithExtensionComments(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithExtensionComments(), gormschema.WithExtensions("citext")).Load(NotebookFile{})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(sql, `CREATE EXTENSION IF NOT EXISTS "citext";
+-- required by idx_notebook_files_name_trgm, idx_notebook_files_path_trgm
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+CREATE TABLE "notebook_files"`), sql)
+	resetSession()
+	sql, err = gormschema.New("postgres", gormschema.WithExtensionComments()).LoadDown(NotebookFile{})
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(sql, "DROP EXTENSION IF EXISTS \"pg_trgm\";\n"), sql)
+	resetSession()
+}
+
+type Ledger struct {
+	ID        uint
+	AccountID uint
+	Tags      []string `gorm:"type:text[]"`
+}
+
+func (Ledger) Indexes() []gormschema.IndexDefinition[Ledger] {
+	return []gormschema.IndexDefinition[Ledger]{
+		{
+			Name:     "idx_ledgers_account",
+			Columns:  []gormschema.Col[Ledger]{gormschema.Field(func(m *Ledger) any { return &m.AccountID })},
+			Workload: gormschema.WriteHeavy,
+		},
+		{
+			Name:     "idx_ledgers_account_id",
+			Columns:  []gormschema.Col[Ledger]{gormschema.Field(func(m *Ledger) any { return &m.ID }), gormschema.Field(func(m *Ledger) any { return &m.AccountID })},
+			Workload: gormschema.WriteHeavy,
+			With:     map[string]string{"fillfactor": "80", "deduplicate_items": "off"},
+		},
+		{
+			Name:     "idx_ledgers_tags",
+			Type:     "gin",
+			Columns:  []gormschema.Col[Ledger]{gormschema.Field(func(m *Ledger) any { return &m.Tags })},
+			Workload: gormschema.ReadHeavy,
+		},
+	}
+}
+
+func TestWorkloadHint(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Ledger{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_ledgers_account" ON "ledgers" ("account_id") WITH (fillfactor=70);
+CREATE INDEX IF NOT EXISTS "idx_ledgers_account_id" ON "ledgers" ("id","account_id") WITH (deduplicate_items=off,fillfactor=80);
+CREATE INDEX IF NOT EXISTS "idx_ledgers_tags" ON "ledgers" USING gin("tags");
+`)
+	resetSession()
+	sql, err = gormschema.New("mysql").Load(Ledger{})
+	require.NoError(t, err)
+	require.NotContains(t, sql, "fillfactor")
+	resetSession()
+}
+
+type TenantNote struct {
+	ID       uint
+	TenantID string `gorm:"index"`
+	Body     string
+	rls      *gormschema.RLSSpec
+}
+
+func (n TenantNote) RLS() *gormschema.RLSSpec { return n.rls }
+
+func TestRowLevelSecurity(t *testing.T) {
+	note := TenantNote{rls: &gormschema.RLSSpec{
+		Force: true,
+		Policies: []gormschema.Policy{
+			{
+				Name:  "tenant_isolation",
+				Roles: []string{"app_user"},
+				Using: "tenant_id = current_setting('app.tenant_id')",
+				Check: "tenant_id = current_setting('app.tenant_id')",
+			},
+		},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(note)
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "tenant_notes" ("id" bigserial NOT NULL,"tenant_id" text,"body" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_tenant_notes_tenant_id" ON "tenant_notes" ("tenant_id");
+ALTER TABLE "tenant_notes" ENABLE ROW LEVEL SECURITY;
+ALTER TABLE "tenant_notes" FORCE ROW LEVEL SECURITY;
+CREATE POLICY "tenant_isolation" ON "tenant_notes" TO "app_user" USING (tenant_id = current_setting('app.tenant_id')) WITH CHECK (tenant_id = current_setting('app.tenant_id'));
+`, sql)
+	resetSession()
+
+	_, err = gormschema.New("mysql").Load(note)
+	require.EqualError(t, err, "row-level security of TenantNote: not supported by mysql")
+	resetSession()
+
+	for _, tt := range []struct {
+		policy gormschema.Policy
+		err    string
+	}{
+		{gormschema.Policy{Name: "p", Command: "merge", Using: "true"}, `policy "p": unsupported command "merge"`},
+		{gormschema.Policy{Name: "p", Command: "select"}, `policy "p": requires a using or check expression`},
+		{gormschema.Policy{Name: "p", Command: "insert", Using: "true"}, `policy "p": insert policies cannot have a using expression`},
+		{gormschema.Policy{Name: "p", Command: "delete", Check: "true"}, `policy "p": delete policies cannot have a check expression`},
+	} {
+		_, err := gormschema.New("postgres").Load(TenantNote{rls: &gormschema.RLSSpec{Policies: []gormschema.Policy{tt.policy}}})
+		require.EqualError(t, err, tt.err)
+		resetSession()
+	}
+}
+
+type Rack struct {
+	ID      uint
+	Aisle   string
+	Level   int
+	indexes []gormschema.IndexDefinition[Rack]
+}
+
+func (r Rack) Indexes() []gormschema.IndexDefinition[Rack] {
+	return r.indexes
+}
+
+func TestZeroColumnIndex(t *testing.T) {
+	for _, def := range []gormschema.IndexDefinition[Rack]{
+		{Name: "idx_racks_location"},
+		{Name: "idx_racks_location", Columns: []gormschema.Col[Rack]{}, Unique: true, Style: gormschema.InlineConstraint},
+		{Name: "idx_racks_location", Include: []gormschema.Col[Rack]{gormschema.Field(func(r *Rack) any { return &r.Level })}},
+	} {
+		resetSession()
+		_, err := gormschema.New("postgres").Load(Rack{indexes: []gormschema.IndexDefinition[Rack]{def}})
+		require.EqualError(t, err, `index "idx_racks_location" has no columns`)
+	}
+	rack := Rack{indexes: []gormschema.IndexDefinition[Rack]{
+		{Name: "idx_racks_location", Columns: []gormschema.Col[Rack]{gormschema.Expr[Rack]("{1} || '-' || {2}",
+			func(r *Rack) any { return &r.Aisle },
+			func(r *Rack) any { return &r.Level },
+		)}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(rack)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_racks_location" ON "racks" (("aisle" || '-' || "level"));`)
+	resetSession()
+}
+
+type Signup struct {
+	ID        uint
+	TenantID  uint
+	Email     string
+	CreatedAt time.Time
+}
+
+func (Signup) Indexes() []gormschema.IndexDefinition[Signup] {
+	return []gormschema.IndexDefinition[Signup]{
+		{
+			Name: "idx_signups_tenant_email",
+			Columns: []gormschema.Col[Signup]{
+				gormschema.Field(func(s *Signup) any { return &s.TenantID }),
+				gormschema.Desc(gormschema.Expr[Signup]("lower(email)")),
+				gormschema.Field(func(s *Signup) any { return &s.CreatedAt }),
+			},
+		},
+	}
+}
+
+func TestExprMixedColumns(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"postgres": `CREATE INDEX IF NOT EXISTS "idx_signups_tenant_email" ON "signups" ("tenant_id",lower(email) desc,"created_at");`,
+		"mysql":    "INDEX `idx_signups_tenant_email` (`tenant_id`,(lower(email)) desc,`created_at`)",
+		"sqlite":   "CREATE INDEX `idx_signups_tenant_email` ON `signups`(`tenant_id`,lower(email) desc,`created_at`);",
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Signup{})
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+		})
+	}
+	resetSession()
+}
+
+type Story struct {
+	ID       uint
+	Headline string
+	Summary  string
+	Body     string
+	// bodyWeight is the weight of the body column of the search index, "C" by default.
+	bodyWeight string
+}
+
+func (st Story) Indexes() []gormschema.IndexDefinition[Story] {
+	return []gormschema.IndexDefinition[Story]{
+		{
+			Name: "idx_stories_search",
+			Type: "gin",
+			Columns: []gormschema.Col[Story]{
+				gormschema.TSVectorWeighted("english",
+					gormschema.Weight(func(s *Story) any { return &s.Headline }, "A"),
+					gormschema.Weight(func(s *Story) any { return &s.Summary }, "B"),
+					gormschema.Weight(func(s *Story) any { return &s.Body }, cmp.Or(st.bodyWeight, "C")),
+				),
+			},
+		},
+	}
+}
+
+func TestTSVectorWeighted(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Story{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_stories_search" ON "stories" USING gin((`+
+		`setweight(to_tsvector('english', coalesce("headline", '')), 'A') || `+
+		`setweight(to_tsvector('english', coalesce("summary", '')), 'B') || `+
+		`setweight(to_tsvector('english', coalesce("body", '')), 'C')));`)
+	for _, dialect := range []string{"mysql", "sqlite", "sqlserver"} {
+		resetSession()
+		_, err = gormschema.New(dialect).Load(Story{})
+		require.EqualError(t, err, `index "idx_stories_search" column 1: text search vectors are supported only by PostgreSQL`, dialect)
+	}
+	resetSession()
+	_, err = gormschema.New("postgres").Load(Story{bodyWeight: "E"})
+	require.EqualError(t, err, `index "idx_stories_search" column 1: invalid weight "E" of column 3, expected A, B, C or D`)
+	resetSession()
+}
+
+type Snippet struct {
+	ID          uint
+	Language    string `gorm:"size:32"`
+	Description string `gorm:"type:text"`
+}
+
+func (Snippet) Indexes() []gormschema.IndexDefinition[Snippet] {
+	return []gormschema.IndexDefinition[Snippet]{
+		{
+			Name: "idx_snippets_language_description",
+			Columns: []gormschema.Col[Snippet]{
+				gormschema.Field(func(s *Snippet) any { return &s.Language }),
+				gormschema.Length(gormschema.Desc(gormschema.Field(func(s *Snippet) any { return &s.Description })), 255),
+			},
+		},
+	}
+}
+
+func TestPrefixLength(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"mysql":    "INDEX `idx_snippets_language_description` (`language`,`description`(255) desc)",
+		"postgres": `CREATE INDEX IF NOT EXISTS "idx_snippets_language_description" ON "snippets" ("language","description" desc);`,
+		"sqlite":   "CREATE INDEX `idx_snippets_language_description` ON `snippets`(`language`,`description` desc);",
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Snippet{})
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+			resetSession()
+		})
+	}
+	resetSession()
+	_, err := gormschema.New("postgres", gormschema.WithStrictPrefixLengths()).Load(Snippet{})
+	require.EqualError(t, err, `index "idx_snippets_language_description" column 2: prefix lengths are supported only by MySQL`)
+	resetSession()
+}
+
+type Manual struct {
+	ID    uint
+	Title string `gorm:"size:255"`
+	Body  string `gorm:"type:text"`
+}
+
+func (Manual) Indexes() []gormschema.IndexDefinition[Manual] {
+	return []gormschema.IndexDefinition[Manual]{
+		{
+			Name: "idx_manuals_search",
+			Type: "fulltext",
+			Columns: []gormschema.Col[Manual]{
+				gormschema.Field(func(m *Manual) any { return &m.Title }),
+				gormschema.Field(func(m *Manual) any { return &m.Body }),
+			},
+		},
+	}
+}
+
+func TestFulltextIndex(t *testing.T) {
+	for dialect, expected := range map[string]string{
+		"mysql":    "FULLTEXT INDEX `idx_manuals_search` (`title`,`body`)",
+		"postgres": `CREATE INDEX IF NOT EXISTS "idx_manuals_search" ON "manuals" USING gin(to_tsvector('simple', coalesce("title", '') || ' ' || coalesce("body", '')));`,
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			resetSession()
+			sql, err := gormschema.New(dialect).Load(Manual{})
+			require.NoError(t, err)
+			require.Contains(t, sql, expected)
+			resetSession()
+		})
+	}
+	resetSession()
+	_, err := gormschema.New("sqlite").Load(Manual{})
+	require.EqualError(t, err, `index "idx_manuals_search": fulltext indexes are supported only by MySQL and PostgreSQL, not sqlite`)
+	resetSession()
+}
+
+// TenantScoped is a base model embedded by the models of tenants.
+type TenantScoped struct {
+	TenantID uint
+}
+
+func (TenantScoped) Indexes() []gormschema.IndexDefinition[TenantScoped] {
+	return []gormschema.IndexDefinition[TenantScoped]{
+		{
+			Name:    "idx_" + gormschema.TablePlaceholder + "_tenant_id",
+			Columns: []gormschema.Col[TenantScoped]{gormschema.Field(func(m *TenantScoped) any { return &m.TenantID })},
+		},
+	}
+}
+
+type Project struct {
+	ID uint
+	TenantScoped
+	Name string
+}
+
+type Folder struct {
+	ID           uint
+	TenantScoped `gorm:"embedded"`
+	Path         string
+}
+
+func TestEmbeddedBaseIndexes(t *testing.T) {
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(Project{}, Folder{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "projects" ("id" bigserial NOT NULL,"tenant_id" bigint,"name" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_projects_tenant_id" ON "projects" ("tenant_id");
+CREATE TABLE "folders" ("id" bigserial NOT NULL,"tenant_id" bigint,"path" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_folders_tenant_id" ON "folders" ("tenant_id");
+`, sql)
+	resetSession()
+
+	// Indexes of a model are merged with the ones of its bases.
+	slug := gormschema.Field(func(m *Squad) any { return &m.Slug })
+	sql, err = gormschema.New("postgres").Load(Squad{indexes: []gormschema.IndexDefinition[Squad]{
+		{Name: "idx_squads_slug", Columns: []gormschema.Col[Squad]{slug}},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "squads" ("id" bigserial NOT NULL,"tenant_id" bigint,"slug" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_squads_slug" ON "squads" ("slug");
+CREATE INDEX IF NOT EXISTS "idx_squads_tenant_id" ON "squads" ("tenant_id");
+`, sql)
+	resetSession()
+	_, err = gormschema.New("postgres").Load(Squad{indexes: []gormschema.IndexDefinition[Squad]{
+		{Name: "idx_{table}_tenant_id", Columns: []gormschema.Col[Squad]{slug}},
+	}})
+	require.EqualError(t, err, `index "idx_squads_tenant_id" declared twice`)
+	resetSession()
+}
+
+// Squad declares its own indexes, in addition to the ones of its base.
+type Squad struct {
+	ID uint
+	*TenantScoped
+	Slug    string
+	indexes []gormschema.IndexDefinition[Squad]
+}
+
+func (s Squad) Indexes() []gormschema.IndexDefinition[Squad] {
+	return s.indexes
+}
+
+type Playlist struct {
+	ID     uint
+	Name   string
+	Tracks []*Track
+}
+
+type Track struct {
+	ID         uint
+	PlaylistID uint
+	Position   int
+}
+
+func (Track) Indexes() []gormschema.IndexDefinition[Track] {
+	return []gormschema.IndexDefinition[Track]{
+		{
+			Name: "idx_tracks_playlist_position",
+			Columns: []gormschema.Col[Track]{
+				gormschema.Field(func(m *Track) any { return &m.PlaylistID }),
+				gormschema.Field(func(m *Track) any { return &m.Position }),
+			},
+			Unique: true,
+		},
+	}
+}
+
+func TestHasManyChildIndexes(t *testing.T) {
+	// The indexes of has-many children are created on their tables, regardless
+	// of whether the parent or the child is loaded first.
+	for _, models := range [][]any{{Playlist{}, Track{}}, {&Track{}, &Playlist{}}} {
+		resetSession()
+		sql, err := gormschema.New("postgres").Load(models...)
+		require.NoError(t, err)
+		require.Contains(t, sql, `CREATE UNIQUE INDEX IF NOT EXISTS "idx_tracks_playlist_position" ON "tracks" ("playlist_id","position");`)
+		require.Contains(t, sql, `ALTER TABLE "tracks" ADD CONSTRAINT "fk_playlists_tracks" FOREIGN KEY ("playlist_id") REFERENCES "playlists"("id");`)
+	}
+	resetSession()
+	sql, err := gormschema.New("mysql").Load(Playlist{}, Track{})
+	require.NoError(t, err)
+	require.Contains(t, sql, "UNIQUE INDEX `idx_tracks_playlist_position` (`playlist_id`,`position`)")
+	// The foreign key is covered by the child index, hence GORM does not create another one.
+	require.NotContains(t, sql, "idx_tracks_playlist_id")
+	resetSession()
+}
+
+type Stamp struct {
+	CreatedBy string
+}
+
+type Memo struct {
+	ID uint
+	Stamp
+	Topic   string
+	Body    string
+	indexes []gormschema.IndexDefinition[Memo]
+}
+
+func (m Memo) Indexes() []gormschema.IndexDefinition[Memo] {
+	return m.indexes
+}
+
+func TestFieldByName(t *testing.T) {
+	memo := Memo{indexes: []gormschema.IndexDefinition[Memo]{
+		{Name: "idx_memos_topic", Columns: []gormschema.Col[Memo]{gormschema.Desc(gormschema.FieldByName[Memo]("Topic")), gormschema.FieldByName[Memo]("CreatedBy")}},
+		{Name: "idx_memos_body", Type: "fulltext", Columns: []gormschema.Col[Memo]{gormschema.FieldByName[Memo]("Topic"), gormschema.FieldByName[Memo]("Body")}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(memo)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_memos_topic" ON "memos" ("topic" desc,"created_by");`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_memos_body" ON "memos" USING gin(to_tsvector('simple', coalesce("topic", '') || ' ' || coalesce("body", '')));`)
+
+	// The selector wins over the field name.
+	col := gormschema.FieldByName[Memo]("Body")
+	col.Sel = func(m *Memo) any { return &m.Topic }
+	memo.indexes = []gormschema.IndexDefinition[Memo]{
+		{Name: "idx_memos_topic", Columns: []gormschema.Col[Memo]{col}},
+	}
+	resetSession()
+	sql, err = gormschema.New("postgres").Load(memo)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_memos_topic" ON "memos" ("topic");`)
+
+	for _, tt := range []struct {
+		col  gormschema.Col[Memo]
+		want string
+	}{
+		{col: gormschema.FieldByName[Memo]("Subject"), want: `index "idx_memos_topic" column 1: FieldName "Subject" is not an exported field of Memo`},
+		{col: gormschema.FieldByName[Memo]("topic"), want: `index "idx_memos_topic" column 1: FieldName "topic" is not an exported field of Memo`},
+		{col: gormschema.Col[Memo]{Sort: "desc"}, want: `index "idx_memos_topic" column 1: missing Sel or FieldName`},
+	} {
+		memo.indexes = []gormschema.IndexDefinition[Memo]{
+			{Name: "idx_memos_topic", Columns: []gormschema.Col[Memo]{tt.col}},
+		}
+		resetSession()
+		_, err = gormschema.New("postgres").Load(memo)
+		require.EqualError(t, err, tt.want)
+	}
+	resetSession()
+}
+
+// Audited is a gorm.Model-style base, embedded by models.
+type Audited struct {
+	ID        uint `gorm:"primarykey"`
+	TenantID  uint
+	CreatedAt time.Time
+	DeletedAt *time.Time
+}
+
+type Journal struct {
+	Audited
+	Title   string
+	indexes []gormschema.IndexDefinition[Journal]
+}
+
+func (j Journal) Indexes() []gormschema.IndexDefinition[Journal] {
+	return j.indexes
+}
+
+type Entry struct {
+	*Audited
+	TenantID string
+	Body     string
+}
+
+func (Entry) Indexes() []gormschema.IndexDefinition[Entry] {
+	return []gormschema.IndexDefinition[Entry]{
+		// TenantID of Entry shadows the one of Audited.
+		{Name: "idx_entries_tenant", Columns: []gormschema.Col[Entry]{gormschema.Field(func(m *Entry) any { return &m.Audited.TenantID })}},
+	}
+}
+
+func TestEmbeddedSelectors(t *testing.T) {
+	journal := Journal{indexes: []gormschema.IndexDefinition[Journal]{
+		{
+			Name: "idx_journals_tenant_created",
+			Columns: []gormschema.Col[Journal]{
+				gormschema.Field(func(m *Journal) any { return &m.Audited.TenantID }),
+				gormschema.Desc(gormschema.Field(func(m *Journal) any { return &m.CreatedAt })),
+			},
+			Where: "deleted_at IS NULL",
+		},
+		// The first field of the embedded struct shares its address with the struct itself.
+		{Name: "idx_journals_id_title", Columns: []gormschema.Col[Journal]{
+			gormschema.Field(func(m *Journal) any { return &m.Audited.ID }),
+			gormschema.Field(func(m *Journal) any { return &m.Title }),
+		}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(journal)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_journals_tenant_created" ON "journals" ("tenant_id","created_at" desc) WHERE deleted_at IS NULL;`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_journals_id_title" ON "journals" ("id","title");`)
+
+	// Selecting the embedded struct itself does not select a column.
+	journal.indexes = []gormschema.IndexDefinition[Journal]{
+		{Name: "idx_journals_audited", Columns: []gormschema.Col[Journal]{gormschema.Field(func(m *Journal) any { return &m.Audited })}},
+	}
+	resetSession()
+	_, err = gormschema.New("postgres").Load(journal)
+	require.EqualError(t, err, `index "idx_journals_audited" column 1: field "Audited" is not mapped to a column`)
+
+	resetSession()
+	_, err = gormschema.New("postgres").Load(Entry{})
+	require.EqualError(t, err, `index "idx_entries_tenant" column 1: Sel didn't point to a top-level exported field on Entry`)
+	resetSession()
+}
+
+// Handle was renamed from the login column, which is still read by the legacy field.
+type Handle struct {
+	ID      uint
+	Name    string `gorm:"column:handle"`
+	Login   string `gorm:"column:login;-:migration"`
+	indexes []gormschema.IndexDefinition[Handle]
+}
+
+func (h Handle) Indexes() []gormschema.IndexDefinition[Handle] {
+	return h.indexes
+}
+
+func TestWithStrictIndexColumns(t *testing.T) {
+	name := gormschema.Field(func(m *Handle) any { return &m.Name })
+	login := gormschema.Field(func(m *Handle) any { return &m.Login })
+	for _, tt := range []struct {
+		def  gormschema.IndexDefinition[Handle]
+		want string
+	}{
+		{
+			def:  gormschema.IndexDefinition[Handle]{Name: "idx_handles_login", Columns: []gormschema.Col[Handle]{gormschema.Class(login, "text_pattern_ops")}},
+			want: `index "idx_handles_login" column 1: column "login" is not created in table handles`,
+		},
+		{
+			def:  gormschema.IndexDefinition[Handle]{Name: "idx_handles_login", Columns: []gormschema.Col[Handle]{gormschema.Class(gormschema.Expr("lower({1})", func(m *Handle) any { return &m.Login }), "text_pattern_ops")}},
+			want: `index "idx_handles_login" column 1: reference 1: column "login" is not created in table handles`,
+		},
+		{
+			def:  gormschema.IndexDefinition[Handle]{Name: "idx_handles_login", Columns: []gormschema.Col[Handle]{name}, Include: []gormschema.Col[Handle]{login}},
+			want: `index "idx_handles_login" included column 1: column "login" is not created in table handles`,
+		},
+	} {
+		handle := Handle{indexes: []gormschema.IndexDefinition[Handle]{tt.def}}
+		resetSession()
+		// The mismatch is caught only in strict mode.
+		sql, err := gormschema.New("postgres").Load(handle)
+		require.NoError(t, err)
+		require.Contains(t, sql, `"login"`)
+		resetSession()
+		_, err = gormschema.New("postgres", gormschema.WithStrictIndexColumns()).Load(handle)
+		require.EqualError(t, err, tt.want)
+	}
+	handle := Handle{indexes: []gormschema.IndexDefinition[Handle]{
+		{Name: "idx_handles_handle", Columns: []gormschema.Col[Handle]{gormschema.Class(name, "text_pattern_ops")}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithStrictIndexColumns()).Load(handle)
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "handles" ("id" bigserial NOT NULL,"handle" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_handles_handle" ON "handles" ("handle" text_pattern_ops);
+`, sql)
+	resetSession()
+}
+
+type NamedWidget struct {
+	ID        uint
+	SerialNo  string
+	CreatedBy string
+}
+
+func (NamedWidget) Indexes() []gormschema.IndexDefinition[NamedWidget] {
+	return []gormschema.IndexDefinition[NamedWidget]{
+		{
+			Name: "idx_serial",
+			Columns: []gormschema.Col[NamedWidget]{
+				gormschema.Class(gormschema.Field(func(m *NamedWidget) any { return &m.SerialNo }), "text_pattern_ops"),
+				gormschema.Expr("lower({1})", func(m *NamedWidget) any { return &m.CreatedBy }),
+			},
+		},
+	}
+}
+
+func TestIndexesNamingStrategy(t *testing.T) {
+	// Column names are computed by the configured naming strategy, not the default one.
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithConfig(&gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			TablePrefix:  "app_",
+			NoLowerCase:  true,
+			NameReplacer: strings.NewReplacer("No", "Number"),
+		},
+	})).Load(NamedWidget{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "app_NamedWidgets" ("ID" bigserial NOT NULL,"SerialNumber" text,"CreatedBy" text,PRIMARY KEY ("ID"));
+CREATE INDEX IF NOT EXISTS "idx_serial" ON "app_NamedWidgets" ("SerialNumber" text_pattern_ops,lower("CreatedBy"));
+`, sql)
+	resetSession()
+}
+
+type Upload struct {
+	ID       uint
+	FileName string
+}
+
+func (Upload) Indexes() []gormschema.IndexDefinition[Upload] {
+	return []gormschema.IndexDefinition[Upload]{
+		{Name: "idx_uploads_file_name", Columns: []gormschema.Col[Upload]{gormschema.Class(gormschema.Field(func(m *Upload) any { return &m.FileName }), "text_pattern_ops")}},
+	}
+}
+
+func TestWithNamingStrategy(t *testing.T) {
+	resetSession()
+	namer := gormschema.WithNamingStrategy(schema.NamingStrategy{NoLowerCase: true})
+	sql, err := gormschema.New("postgres", namer).Load(Upload{})
+	require.NoError(t, err)
+	require.Equal(t, `CREATE TABLE "Uploads" ("ID" bigserial NOT NULL,"FileName" text,PRIMARY KEY ("ID"));
+CREATE INDEX IF NOT EXISTS "idx_uploads_file_name" ON "Uploads" ("FileName" text_pattern_ops);
+`, sql)
+	// The naming strategy overrides the one of the config.
+	resetSession()
+	cfg := gormschema.WithConfig(&gorm.Config{NamingStrategy: schema.NamingStrategy{TablePrefix: "app_"}})
+	sql, err = gormschema.New("postgres", cfg, namer).Load(Upload{})
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_uploads_file_name" ON "Uploads" ("FileName" text_pattern_ops);`)
+	resetSession()
+}
+
+func TestPartialIndexDialects(t *testing.T) {
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	for _, tt := range []struct {
+		dialect, where, want, err string
+	}{
+		{dialect: "postgres", where: "status <> '' OR total > 0", want: `CREATE INDEX IF NOT EXISTS "idx_orders_status" ON "orders" ("status") WHERE status <> '' OR total > 0;`},
+		{dialect: "mysql", where: "status <> ''", err: `index "idx_orders_status": partial indexes are not supported by mysql`},
+		{dialect: "sqlite", where: "status <> 'now()'", want: "CREATE INDEX `idx_orders_status` ON `orders`(`status`) WHERE status <> 'now()';"},
+		{dialect: "sqlite", where: "total > random()", err: `index "idx_orders_status": where of a partial index must be deterministic on sqlite, but calls random()`},
+		{dialect: "sqlite", where: "total > (SELECT 0)", err: `index "idx_orders_status": where of a partial index must not contain subqueries on sqlite`},
+		{dialect: "sqlserver", where: "status IS NOT NULL AND (total IN (1, 2))", want: `CREATE INDEX "idx_orders_status" ON "orders"("status") WHERE status IS NOT NULL AND (total IN (1, 2));`},
+		{dialect: "sqlserver", where: "status = 'a' OR total > 0", err: `index "idx_orders_status": filtered indexes of sqlserver support only comparisons, IN and IS [NOT] NULL combined with AND, but where uses OR`},
+		{dialect: "sqlserver", where: "status like 'a%'", err: `index "idx_orders_status": filtered indexes of sqlserver support only comparisons, IN and IS [NOT] NULL combined with AND, but where uses LIKE`},
+		{dialect: "sqlserver", where: "total <> NULL", err: `index "idx_orders_status": filtered indexes of sqlserver support only comparisons, IN and IS [NOT] NULL combined with AND, but where uses <> NULL`},
+		{dialect: "sqlserver", where: "len(status) > 0", err: `index "idx_orders_status": filtered indexes of sqlserver support only comparisons, IN and IS [NOT] NULL combined with AND, but where uses LEN()`},
+	} {
+		t.Run(tt.dialect+"/"+tt.where, func(t *testing.T) {
+			order := Order{indexes: []gormschema.IndexDefinition[Order]{
+				{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Where: tt.where},
+			}}
+			resetSession()
+			sql, err := gormschema.New(tt.dialect).Load(order)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Contains(t, sql, tt.want)
+		})
+	}
+	resetSession()
+}
+
+func TestPredicateColumns(t *testing.T) {
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	for _, tt := range []struct {
+		dialect, where string
+		unknown        []string
+	}{
+		{dialect: "postgres", where: "satus <> ''", unknown: []string{"satus"}},
+		{dialect: "postgres", where: `"status" IS NOT NULL AND orders.totl > 0`, unknown: []string{"totl"}},
+		{dialect: "postgres", where: "lower(status) <> 'a b' AND total::numeric > 0 AND customer_id IS NOT NULL"},
+		{dialect: "postgres", where: "status <> ALL (ARRAY['x']) AND CAST(total AS bigint) > 0 AND total::double precision < 1e5"},
+		{dialect: "postgres", where: "status IS DISTINCT FROM E'x' AND total > interval '1 day' OR TRUE"},
+		{dialect: "sqlite", where: "[status] <> '' AND `custmer_id` > 0", unknown: []string{"custmer_id"}},
+		{dialect: "sqlserver", where: "status IN ('a', 'b') AND total IS NULL"},
+	} {
+		t.Run(tt.dialect+"/"+tt.where, func(t *testing.T) {
+			order := Order{indexes: []gormschema.IndexDefinition[Order]{
+				{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Where: tt.where},
+			}}
+			resetSession()
+			l := &warnLogger{Interface: logger.Discard}
+			_, err := gormschema.New(tt.dialect, gormschema.WithLogger(l), gormschema.WithPredicateColumnWarnings()).Load(order)
+			require.NoError(t, err)
+			var warns []string
+			for _, c := range tt.unknown {
+				warns = append(warns, fmt.Sprintf("index %q: where references unknown column %q of table orders", "idx_orders_status", c))
+			}
+			require.Equal(t, warns, l.warns)
+
+			resetSession()
+			_, err = gormschema.New(tt.dialect, gormschema.WithStrictPredicateColumns()).Load(order)
+			if len(tt.unknown) > 0 {
+				require.EqualError(t, err, warns[0])
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	// Predicates are not checked by default.
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Where: "satus <> ''"},
+	}}
+	resetSession()
+	l := &warnLogger{Interface: logger.Discard}
+	_, err := gormschema.New("postgres", gormschema.WithLogger(l)).Load(order)
+	require.NoError(t, err)
+	require.Empty(t, l.warns)
+	resetSession()
+}
+
+func TestDescribeIndexes(t *testing.T) {
+	title := gormschema.Field(func(m *Article) any { return &m.Title })
+	body := gormschema.Field(func(m *Article) any { return &m.Body })
+	article := Article{indexes: []gormschema.IndexDefinition[Article]{
+		{Name: "idx_{table}_title", Columns: []gormschema.Col[Article]{gormschema.NullsLast(gormschema.Desc(title))}, Include: []gormschema.Col[Article]{body}, Unique: true, Where: "title <> ''"},
+		{Name: "idx_articles_body", Columns: []gormschema.Col[Article]{gormschema.Class(body, "gin_trgm_ops")}},
+		{Name: "idx_articles_lower_title", Columns: []gormschema.Col[Article]{gormschema.Expr("lower({1})", func(m *Article) any { return &m.Title })}},
+		{Name: "uq_articles_body", Columns: []gormschema.Col[Article]{body}, Unique: true, Style: gormschema.AlterConstraint},
+	}}
+	indexes, err := gormschema.New("postgres").DescribeIndexes(&article)
+	require.NoError(t, err)
+	buf, err := json.Marshal(indexes)
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+		{"name": "idx_articles_title", "table": "articles", "columns": [{"name": "title", "sort": "desc", "nulls": "last"}], "include": ["body"], "unique": true, "where": "title <> ''"},
+		{"name": "idx_articles_body", "table": "articles", "columns": [{"name": "body", "opclass": "gin_trgm_ops"}], "type": "gin", "extensions": ["pg_trgm"]},
+		{"name": "idx_articles_lower_title", "table": "articles", "columns": [{"expr": "lower(\"title\")"}]}
+	]`, string(buf))
+	// Indexes are resolved for the dialect, naming strategy and schema of the loader.
+	indexes, err = gormschema.New("postgres", gormschema.WithSchema("app")).DescribeIndexes(article)
+	require.NoError(t, err)
+	require.Equal(t, "app.articles", indexes[0].Table)
+	_, err = gormschema.New("sqlite").DescribeIndexes(article)
+	require.EqualError(t, err, `index "idx_{table}_title": included columns are supported only by PostgreSQL and SQL Server`)
+
+	article.indexes = []gormschema.IndexDefinition[Article]{
+		{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{title}},
+		{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{body}},
+	}
+	_, err = gormschema.New("postgres").DescribeIndexes(article)
+	require.EqualError(t, err, `index "idx_articles_title" declared twice`)
+
+	// Fulltext indexes are described as created for the dialect of the loader.
+	indexes, err = gormschema.New("postgres").DescribeIndexes(Manual{})
+	require.NoError(t, err)
+	require.Equal(t, []gormschema.ResolvedIndex{{
+		Name:    "idx_manuals_search",
+		Table:   "manuals",
+		Columns: []gormschema.ResolvedColumn{{Expr: `to_tsvector('simple', coalesce("title", '') || ' ' || coalesce("body", ''))`}},
+		Type:    "gin",
+	}}, indexes)
+	indexes, err = gormschema.New("mysql").DescribeIndexes(Manual{})
+	require.NoError(t, err)
+	require.Equal(t, []gormschema.ResolvedIndex{{
+		Name:    "idx_manuals_search",
+		Table:   "manuals",
+		Columns: []gormschema.ResolvedColumn{{Name: "title"}, {Name: "body"}},
+		Type:    "fulltext",
+	}}, indexes)
+
+	// Extensions of known operator classes are reported with their pinned versions.
+	known := gormschema.WithKnownOpClasses(map[string]gormschema.OpClassInfo{"vector_l2_ops": {Method: "hnsw", Extension: "vector"}})
+	indexes, err = gormschema.New("postgres", known, gormschema.WithExtensions("vector:0.7.0")).DescribeIndexes(Embedding{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"vector:0.7.0"}, indexes[0].Extensions)
+	_, err = gormschema.New("postgres", known, gormschema.WithExtensions("vector:0.7.0", "vector:0.8.0")).DescribeIndexes(Embedding{})
+	require.EqualError(t, err, `extension "vector" is required with versions 0.7.0 and 0.8.0`)
+
+	indexes, err = gormschema.New("postgres").DescribeIndexes(Note{})
+	require.NoError(t, err)
+	require.Empty(t, indexes)
+	_, err = gormschema.New("postgres").DescribeIndexes(nil)
+	require.EqualError(t, err, "model must be a struct or *struct, got <nil>")
+}
+
+func TestInvalidSortOrder(t *testing.T) {
+	title := gormschema.Field(func(m *Article) any { return &m.Title })
+	body := gormschema.Field(func(m *Article) any { return &m.Body })
+	for _, tt := range []struct {
+		col  gormschema.Col[Article]
+		want string
+	}{
+		{col: gormschema.Col[Article]{Sel: title.Sel, Sort: "ascending"}, want: `index "idx_articles_title" column 2: invalid sort "ascending", expected "asc" or "desc"`},
+		{col: gormschema.Col[Article]{Sel: title.Sel, Sort: "desc nulls last"}, want: `index "idx_articles_title" column 2: invalid sort "desc nulls last", expected "asc" or "desc"`},
+		{col: gormschema.Col[Article]{Sel: title.Sel, Nulls: "lst"}, want: `index "idx_articles_title" column 2: invalid nulls "lst", expected "first" or "last"`},
+		{col: gormschema.Col[Article]{Sel: title.Sel, Sort: "DESC", Nulls: "LAST"}},
+	} {
+		article := Article{indexes: []gormschema.IndexDefinition[Article]{
+			{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{body, tt.col}},
+		}}
+		resetSession()
+		_, err := gormschema.New("postgres").Load(article)
+		if tt.want == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, tt.want)
+	}
+	resetSession()
+}
+
+func TestNullsNotDistinct(t *testing.T) {
+	customer := gormschema.Field(func(m *Order) any { return &m.CustomerID })
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "uq_orders_customer", Columns: []gormschema.Col[Order]{customer}, Include: []gormschema.Col[Order]{status}, Unique: true, NullsNotDistinct: true, With: map[string]string{"fillfactor": "70"}},
+		{Name: "uq_orders_status", Columns: []gormschema.Col[Order]{status}, Unique: true, NullsNotDistinct: true, Style: gormschema.AlterConstraint},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(order)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE UNIQUE INDEX IF NOT EXISTS "uq_orders_customer" ON "orders" ("customer_id") INCLUDE ("status") NULLS NOT DISTINCT WITH (fillfactor=70);`)
+	require.Contains(t, sql, `ALTER TABLE "orders" ADD CONSTRAINT "uq_orders_status" UNIQUE NULLS NOT DISTINCT ("status");`)
+
+	// SQL Server treats NULLs as equal values of unique indexes.
+	resetSession()
+	l := &warnLogger{Interface: logger.Discard}
+	sql, err = gormschema.New("sqlserver", gormschema.WithLogger(l)).Load(order)
+	require.NoError(t, err)
+	require.NotContains(t, sql, "NULLS NOT DISTINCT")
+	require.Empty(t, l.warns)
+
+	order.indexes[0].Include = nil
+	resetSession()
+	l = &warnLogger{Interface: logger.Discard}
+	sql, err = gormschema.New("mysql", gormschema.WithLogger(l)).Load(order)
+	require.NoError(t, err)
+	require.NotContains(t, sql, "NULLS NOT DISTINCT")
+	require.Equal(t, []string{
+		`index "uq_orders_customer": NULLS NOT DISTINCT is supported only by PostgreSQL and SQL Server and was ignored`,
+		`index "uq_orders_status": NULLS NOT DISTINCT is supported only by PostgreSQL and SQL Server and was ignored`,
+	}, l.warns)
+	resetSession()
+	_, err = gormschema.New("mysql", gormschema.WithStrictNullsNotDistinct()).Load(order)
+	require.EqualError(t, err, `index "uq_orders_status": NULLS NOT DISTINCT is supported only by PostgreSQL and SQL Server`)
+
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, NullsNotDistinct: true},
+	}
+	resetSession()
+	_, err = gormschema.New("postgres").Load(order)
+	require.EqualError(t, err, `index "idx_orders_customer": NullsNotDistinct requires a Unique definition`)
+	resetSession()
+}
+
+func TestOpClassParams(t *testing.T) {
+	title := gormschema.Field(func(m *Article) any { return &m.Title })
+	body := gormschema.Field(func(m *Article) any { return &m.Body })
+	article := Article{indexes: []gormschema.IndexDefinition[Article]{
+		{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{gormschema.ClassWithOptions(title, "gist_trgm_ops", map[string]string{"siglen": "256"})}},
+		{Name: "idx_articles_body", Columns: []gormschema.Col[Article]{gormschema.ClassWithOptions(body, "app.custom_ops", map[string]string{"siglen": "64", "Depth": "8"})}, Type: "gist"},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(article)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE EXTENSION IF NOT EXISTS "pg_trgm";`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_articles_title" ON "articles" USING gist("title" gist_trgm_ops (siglen=256));`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_articles_body" ON "articles" USING gist("body" app.custom_ops (depth=8, siglen=64));`)
+
+	for _, tt := range []struct {
+		col  gormschema.Col[Article]
+		want string
+	}{
+		{col: gormschema.ClassWithOptions(title, "gist_trgm_ops", map[string]string{"siglen": "256)"}), want: `index "idx_articles_title" column 1: invalid operator class parameter siglen=256)`},
+		{col: gormschema.Col[Article]{Sel: title.Sel, OpClassParams: map[string]string{"siglen": "256"}}, want: `index "idx_articles_title" column 1: operator class parameters require an operator class`},
+	} {
+		article.indexes = []gormschema.IndexDefinition[Article]{
+			{Name: "idx_articles_title", Columns: []gormschema.Col[Article]{tt.col}},
+		}
+		resetSession()
+		_, err := gormschema.New("postgres").Load(article)
+		require.EqualError(t, err, tt.want)
+	}
+	resetSession()
+}
+
+func TestAccessMethods(t *testing.T) {
+	customer := gormschema.Field(func(m *Order) any { return &m.CustomerID })
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	total := gormschema.Field(func(m *Order) any { return &m.Total })
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_total", Columns: []gormschema.Col[Order]{total}, Type: "brin"},
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Type: "hash"},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres", gormschema.WithStrictAccessMethods()).Load(order)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_total" ON "orders" USING brin("total");`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_status" ON "orders" USING hash("status");`)
+
+	for _, tt := range []struct {
+		def  gormschema.IndexDefinition[Order]
+		opts []gormschema.Option
+		want string
+	}{
+		{
+			def:  gormschema.IndexDefinition[Order]{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status, customer}, Type: "hash"},
+			want: `index "idx_orders_status": hash indexes support a single column, but it has 2`,
+		},
+		{
+			def:  gormschema.IndexDefinition[Order]{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Include: []gormschema.Col[Order]{customer}, Type: "HASH"},
+			want: `index "idx_orders_status": hash indexes support a single column, but it has 2`,
+		},
+		{
+			def:  gormschema.IndexDefinition[Order]{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Unique: true, Type: "hash"},
+			want: `index "idx_orders_status": hash indexes cannot be unique`,
+		},
+		{
+			def:  gormschema.IndexDefinition[Order]{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Type: "bree"},
+			opts: []gormschema.Option{gormschema.WithStrictAccessMethods()},
+			want: `index "idx_orders_status": unknown access method "bree", expected one of btree, hash, gist, gin, spgist, brin, or the method of an operator class registered using WithKnownOpClasses`,
+		},
+		// Unknown methods are allowed unless the loader is strict.
+		{def: gormschema.IndexDefinition[Order]{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Type: "bree"}},
+		{
+			def: gormschema.IndexDefinition[Order]{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Type: "rum"},
+			opts: []gormschema.Option{gormschema.WithStrictAccessMethods(), gormschema.WithKnownOpClasses(map[string]gormschema.OpClassInfo{
+				"rum_tsvector_ops": {Method: "rum", Extension: "rum"},
+			})},
+		},
+	} {
+		order.indexes = []gormschema.IndexDefinition[Order]{tt.def}
+		resetSession()
+		_, err := gormschema.New("postgres", tt.opts...).Load(order)
+		if tt.want == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, tt.want)
+	}
+	// Other dialects do not validate the methods of PostgreSQL.
+	order.indexes = []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status, customer}, Type: "hash"},
+	}
+	resetSession()
+	_, err = gormschema.New("mysql", gormschema.WithStrictAccessMethods()).Load(order)
+	require.NoError(t, err)
+	resetSession()
+}
+
+func TestStorageParameters(t *testing.T) {
+	customer := gormschema.Field(func(m *Order) any { return &m.CustomerID })
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	total := gormschema.Field(func(m *Order) any { return &m.Total })
+	order := Order{indexes: []gormschema.IndexDefinition[Order]{
+		{Name: "idx_orders_total", Columns: []gormschema.Col[Order]{total}, Type: "brin", With: map[string]string{"pages_per_range": "128", "autosummarize": "on"}},
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{gormschema.Class(status, "gin_trgm_ops")}, Type: "gin", With: map[string]string{"gin_pending_list_limit": "4096"}},
+		{Name: "idx_orders_customer", Columns: []gormschema.Col[Order]{customer}, With: map[string]string{"FillFactor": "90"}},
+	}}
+	resetSession()
+	sql, err := gormschema.New("postgres").Load(order)
+	require.NoError(t, err)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_total" ON "orders" USING brin("total") WITH (autosummarize=on,pages_per_range=128);`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_status" ON "orders" USING gin("status" gin_trgm_ops) WITH (gin_pending_list_limit=4096);`)
+	require.Contains(t, sql, `CREATE INDEX IF NOT EXISTS "idx_orders_customer" ON "orders" ("customer_id") WITH (fillfactor=90);`)
+	// Dialects without storage parameters ignore them.
+	for _, dialect := range []string{"mysql", "sqlite"} {
+		resetSession()
+		sql, err = gormschema.New(dialect).Load(order)
+		require.NoError(t, err)
+		require.NotContains(t, sql, "pages_per_range")
+		require.NotContains(t, sql, "fillfactor")
+	}
+
+	for _, tt := range []struct {
+		dialect string
+		def     gormschema.IndexDefinition[Order]
+		want    string
+	}{
+		{
+			dialect: "postgres",
+			def:     gormschema.IndexDefinition[Order]{Name: "idx_orders_total", Columns: []gormschema.Col[Order]{total}, Type: "btree", With: map[string]string{"pages_per_range": "128"}},
+			want:    `index "idx_orders_total": storage parameter "pages_per_range" is not supported by btree indexes, expected one of fillfactor, deduplicate_items`,
+		},
+		{
+			dialect: "postgres",
+			def:     gormschema.IndexDefinition[Order]{Name: "idx_orders_total", Columns: []gormschema.Col[Order]{total}, Type: "BRIN", With: map[string]string{"fillfactor": "70"}},
+			want:    `index "idx_orders_total": storage parameter "fillfactor" is not supported by brin indexes, expected one of pages_per_range, autosummarize`,
+		},
+		{
+			dialect: "sqlserver",
+			def:     gormschema.IndexDefinition[Order]{Name: "idx_orders_total", Columns: []gormschema.Col[Order]{total}, With: map[string]string{"pages_per_range": "128"}},
+			want:    `index "idx_orders_total": storage parameter "pages_per_range" is not supported by sqlserver`,
+		},
+		// The parameters of extension methods are not validated.
+		{
+			dialect: "postgres",
+			def:     gormschema.IndexDefinition[Order]{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Type: "rum", With: map[string]string{"attach": "status"}},
+		},
+		{
+			dialect: "sqlserver",
+			def:     gormschema.IndexDefinition[Order]{Name: "idx_orders_total", Columns: []gormschema.Col[Order]{total}, With: map[string]string{"data_compression": "page"}},
+		},
+	} {
+		order.indexes = []gormschema.IndexDefinition[Order]{tt.def}
+		resetSession()
+		_, err := gormschema.New(tt.dialect).Load(order)
+		if tt.want == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, tt.want)
+	}
+	resetSession()
+}
+
+func TestIndexBuilder(t *testing.T) {
+	customer := gormschema.Field(func(m *Order) any { return &m.CustomerID })
+	status := gormschema.Field(func(m *Order) any { return &m.Status })
+	total := gormschema.Field(func(m *Order) any { return &m.Total })
+	literal := []gormschema.IndexDefinition[Order]{
+		{
+			Name:    "idx_orders_customer",
+			Columns: []gormschema.Col[Order]{customer, gormschema.Desc(total)},
+			Include: []gormschema.Col[Order]{status},
+			Unique:  true,
+			Where:   "total > 0",
+			Conds:   []gormschema.Cond[Order]{gormschema.WhereEq(func(m *Order) any { return &m.Status }, "paid")},
+			With:    map[string]string{"fillfactor": "80"},
+		},
+		{Name: "idx_orders_total", Columns: []gormschema.Col[Order]{total}, Type: "brin", With: map[string]string{"pages_per_range": "64"}},
+		{Name: "idx_orders_status", Columns: []gormschema.Col[Order]{status}, Type: "hash", Concurrent: true, Disabled: true, Workload: gormschema.WriteHeavy},
+	}
+	built := []gormschema.IndexDefinition[Order]{
+		gormschema.NewIndex[Order]("idx_orders_customer").
+			Unique().
+			Where("total > 0").
+			Conds(gormschema.WhereEq(func(m *Order) any { return &m.Status }, "paid")).
+			Add(customer, gormschema.Desc(total)).
+			Include(status).
+			With("fillfactor", "80").
+			Build(),
+		gormschema.NewIndex[Order]("idx_orders_total").Type("brin").Add(total).With("pages_per_range", "64").Build(),
+		gormschema.NewIndex[Order]("idx_orders_status").Type("hash").Add(status).Concurrent().Disabled().Workload(gormschema.WriteHeavy).Build(),
+	}
+	for i := range literal {
+		require.Equal(t, literal[i].Name, built[i].Name)
+		require.Equal(t, len(literal[i].Columns), len(built[i].Columns))
+		require.Equal(t, literal[i].With, built[i].With)
+		require.Equal(t, literal[i].Disabled, built[i].Disabled)
+	}
+	load := func(defs []gormschema.IndexDefinition[Order]) ([]gormschema.ResolvedIndex, string) {
+		order := Order{indexes: defs}
+		resetSession()
+		indexes, err := gormschema.New("postgres").DescribeIndexes(order)
+		require.NoError(t, err)
+		resetSession()
+		sql, err := gormschema.New("postgres").Load(order)
+		require.NoError(t, err)
+		return indexes, sql
+	}
+	want, wantSQL := load(literal)
+	got, gotSQL := load(built)
+	require.Equal(t, want, got)
+	require.Equal(t, wantSQL, gotSQL)
+	require.Contains(t, gotSQL, `USING brin("total") WITH (pages_per_range=64);`)
+
+	// Built definitions do not share their slices and map with the builder.
+	b := gormschema.NewIndex[Order]("idx_orders_customer").Add(customer).With("fillfactor", "80")
+	first := b.Build()
+	second := b.Add(status).With("fillfactor", "70").Build()
+	require.Len(t, first.Columns, 1)
+	require.Len(t, second.Columns, 2)
+	require.Equal(t, map[string]string{"fillfactor": "80"}, first.With)
+	require.Equal(t, map[string]string{"fillfactor": "70"}, second.With)
+	resetSession()
+}
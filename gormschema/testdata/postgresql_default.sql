@@ -1,13 +1,13 @@
-CREATE TABLE "events" ("eventId" varchar(191),"locationId" varchar(191),PRIMARY KEY ("eventId"));
+CREATE TABLE "events" ("eventId" varchar(191) NOT NULL,"locationId" varchar(191),PRIMARY KEY ("eventId"));
 CREATE UNIQUE INDEX IF NOT EXISTS "idx_events_location_id" ON "events" ("locationId");
-CREATE TABLE "locations" ("locationId" varchar(191),"eventId" varchar(191),PRIMARY KEY ("locationId"));
+CREATE TABLE "locations" ("locationId" varchar(191) NOT NULL,"eventId" varchar(191),PRIMARY KEY ("locationId"));
 CREATE UNIQUE INDEX IF NOT EXISTS "idx_locations_event_id" ON "locations" ("eventId");
-CREATE TABLE "user_pet_histories" ("user_id" bigint,"pet_id" bigint,"created_at" timestamptz,PRIMARY KEY ("user_id","pet_id"));
-CREATE TABLE "users" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"age" bigint,PRIMARY KEY ("id"));
+CREATE TABLE "user_pet_histories" ("user_id" bigint NOT NULL,"pet_id" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("user_id","pet_id"));
+CREATE TABLE "users" ("id" bigserial NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"age" bigint,PRIMARY KEY ("id"));
 CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
-CREATE TABLE "hobbies" ("id" bigserial,"name" text,PRIMARY KEY ("id"));
-CREATE TABLE "user_hobbies" ("hobby_id" bigint,"user_id" bigint,PRIMARY KEY ("hobby_id","user_id"));
-CREATE TABLE "pets" ("id" bigserial,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"user_id" bigint,PRIMARY KEY ("id"));
+CREATE TABLE "hobbies" ("id" bigserial NOT NULL,"name" text,PRIMARY KEY ("id"));
+CREATE TABLE "user_hobbies" ("hobby_id" bigint NOT NULL,"user_id" bigint NOT NULL,PRIMARY KEY ("hobby_id","user_id"));
+CREATE TABLE "pets" ("id" bigserial NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"name" text,"user_id" bigint,PRIMARY KEY ("id"));
 CREATE INDEX IF NOT EXISTS "idx_pets_deleted_at" ON "pets" ("deleted_at");
 CREATE VIEW working_aged_users AS SELECT name, age FROM "users" WHERE age BETWEEN 18 AND 65;
 CREATE VIEW top_pet_owners AS SELECT user_id, COUNT(id) AS pet_count FROM pets GROUP BY user_id ORDER BY pet_count DESC LIMIT 10;
@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+CREATE TABLE "issues" ("id" bigserial NOT NULL,"title" text,"priority" bigint,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_issues_priority_title" ON "issues" ("priority" desc,"title" asc);
+CREATE INDEX IF NOT EXISTS "idx_issues_title_pattern" ON "issues" ("title" text_pattern_ops asc nulls last,"priority" asc nulls first);
+CREATE INDEX IF NOT EXISTS "idx_issues_title_trgm" ON "issues" USING gin("title" gin_trgm_ops);
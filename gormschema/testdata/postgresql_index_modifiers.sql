@@ -0,0 +1,3 @@
+CREATE TABLE "contacts" ("id" bigserial NOT NULL,"name" text,"email" text,"phone" text,PRIMARY KEY ("id"));
+CREATE INDEX IF NOT EXISTS "idx_contacts_name_email" ON "contacts" ("name" text_pattern_ops asc nulls last,"email" desc nulls first);
+CREATE INDEX IF NOT EXISTS "idx_contacts_phone" ON "contacts" ("phone" text_pattern_ops nulls first);
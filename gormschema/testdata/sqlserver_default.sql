@@ -1,22 +1,22 @@
-CREATE TABLE "events" ("eventId" nvarchar(191),"locationId" nvarchar(191),PRIMARY KEY ("eventId"))
+CREATE TABLE "events" ("eventId" nvarchar(191) NOT NULL,"locationId" nvarchar(191),PRIMARY KEY ("eventId"))
 GO
 CREATE UNIQUE INDEX "idx_events_location_id" ON "events"("locationId")
 GO
-CREATE TABLE "locations" ("locationId" nvarchar(191),"eventId" nvarchar(191),PRIMARY KEY ("locationId"))
+CREATE TABLE "locations" ("locationId" nvarchar(191) NOT NULL,"eventId" nvarchar(191),PRIMARY KEY ("locationId"))
 GO
 CREATE UNIQUE INDEX "idx_locations_event_id" ON "locations"("eventId")
 GO
-CREATE TABLE "user_pet_histories" ("user_id" bigint,"pet_id" bigint,"created_at" datetimeoffset,PRIMARY KEY ("user_id","pet_id"))
+CREATE TABLE "user_pet_histories" ("user_id" bigint NOT NULL,"pet_id" bigint NOT NULL,"created_at" datetimeoffset,PRIMARY KEY ("user_id","pet_id"))
 GO
-CREATE TABLE "users" ("id" bigint IDENTITY(1,1),"created_at" datetimeoffset,"updated_at" datetimeoffset,"deleted_at" datetimeoffset,"name" nvarchar(MAX),"age" bigint,PRIMARY KEY ("id"))
+CREATE TABLE "users" ("id" bigint IDENTITY(1,1) NOT NULL,"created_at" datetimeoffset,"updated_at" datetimeoffset,"deleted_at" datetimeoffset,"name" nvarchar(MAX),"age" bigint,PRIMARY KEY ("id"))
 GO
 CREATE INDEX "idx_users_deleted_at" ON "users"("deleted_at")
 GO
-CREATE TABLE "hobbies" ("id" bigint IDENTITY(1,1),"name" nvarchar(MAX),PRIMARY KEY ("id"))
+CREATE TABLE "hobbies" ("id" bigint IDENTITY(1,1) NOT NULL,"name" nvarchar(MAX),PRIMARY KEY ("id"))
 GO
-CREATE TABLE "user_hobbies" ("hobby_id" bigint,"user_id" bigint,PRIMARY KEY ("hobby_id","user_id"))
+CREATE TABLE "user_hobbies" ("hobby_id" bigint NOT NULL,"user_id" bigint NOT NULL,PRIMARY KEY ("hobby_id","user_id"))
 GO
-CREATE TABLE "pets" ("id" bigint IDENTITY(1,1),"created_at" datetimeoffset,"updated_at" datetimeoffset,"deleted_at" datetimeoffset,"name" nvarchar(MAX),"user_id" bigint,PRIMARY KEY ("id"))
+CREATE TABLE "pets" ("id" bigint IDENTITY(1,1) NOT NULL,"created_at" datetimeoffset,"updated_at" datetimeoffset,"deleted_at" datetimeoffset,"name" nvarchar(MAX),"user_id" bigint,PRIMARY KEY ("id"))
 GO
 CREATE INDEX "idx_pets_deleted_at" ON "pets"("deleted_at")
 GO
@@ -62,4 +62,3 @@ GO
 ALTER TABLE "user_hobbies" ADD CONSTRAINT "fk_user_hobbies_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
 GO
 ALTER TABLE "pets" ADD CONSTRAINT "fk_users_pets" FOREIGN KEY ("user_id") REFERENCES "users"("id")
-GO
@@ -36,3 +36,15 @@ func (TopCrowdedAddresses) ViewDef(dialect string) []gormschema.ViewOption {
 		gormschema.CreateStmt("CREATE VIEW top_crowded_addresses AS SELECT address_id, COUNT(person_id) AS count FROM person_addresses GROUP BY address_id ORDER BY count DESC LIMIT 10"),
 	}
 }
+
+func (PersonAddress) Indexes() []gormschema.IndexDefinition[PersonAddress] {
+	return []gormschema.IndexDefinition[PersonAddress]{
+		{
+			Name: "idx_person_addresses_address_created",
+			Columns: []gormschema.Col[PersonAddress]{
+				gormschema.Field(func(m *PersonAddress) any { return &m.AddressID }),
+				gormschema.Desc(gormschema.Field(func(m *PersonAddress) any { return &m.CreatedAt })),
+			},
+		},
+	}
+}
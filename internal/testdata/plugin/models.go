@@ -0,0 +1,36 @@
+package plugin
+
+import "ariga.io/atlas-provider-gorm/gormschema"
+
+// Schema is implemented by the models of the plugin.
+type Schema interface {
+	TableName() string
+}
+
+// Models returns the models of the plugin, whose types are not exported.
+func Models() []Schema {
+	return []Schema{widget{}}
+}
+
+type widget struct {
+	ID       uint
+	TenantID uint
+	Slug     string
+}
+
+func (widget) TableName() string {
+	return "plugin_widgets"
+}
+
+func (widget) Indexes() []gormschema.IndexDefinition[widget] {
+	return []gormschema.IndexDefinition[widget]{
+		{
+			Name: "idx_plugin_widgets_tenant_slug",
+			Columns: []gormschema.Col[widget]{
+				gormschema.Field(func(m *widget) any { return &m.TenantID }),
+				gormschema.Field(func(m *widget) any { return &m.Slug }),
+			},
+			Unique: true,
+		},
+	}
+}